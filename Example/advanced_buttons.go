@@ -6,12 +6,17 @@ import (
 	"time"
 )
 
+type ratingData struct {
+	Stars int
+}
+
 func main() {
 	fmt.Println("🚀 Starting Rubika Bot with Advanced Buttons...")
 	
 	bot := NewRobot("BOT_TOKEN",
 		WithTimeout(30*time.Second),
 		WithPlatform("android"),
+		WithCallbackSecret([]byte("CHANGE_ME")),
 	)
 
 	bot.OnMessage(func(r *Robot, m *Message) {
@@ -110,64 +115,67 @@ func main() {
 	})
 
 	bot.OnCallback("btn_info", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "🤖 *اطلاعات ربات:*\n\n• نام: ربات تست\n• نسخه: 1.0.0\n• حالت: Polling", nil)
+		r.SendMessage(m.ChatID, "🤖 *اطلاعات ربات:*\n\n• نام: ربات تست\n• نسخه: 1.0.0\n• حالت: Polling")
 	})
 
 	bot.OnCallback("btn_rating", func(r *Robot, m *Message) {
-		star1 := CreateInlineButton("⭐", "star_1", "Simple")
-		star2 := CreateInlineButton("⭐⭐", "star_2", "Simple")
-		star3 := CreateInlineButton("⭐⭐⭐", "star_3", "Simple")
-		star4 := CreateInlineButton("⭐⭐⭐⭐", "star_4", "Simple")
-		star5 := CreateInlineButton("⭐⭐⭐⭐⭐", "star_5", "Simple")
+		var stars []map[string]interface{}
+		for i := 1; i <= 5; i++ {
+			star, err := r.CallbackButton(strings.Repeat("⭐", i), "rate", ratingData{Stars: i})
+			if err != nil {
+				fmt.Printf("❌ Error: %v\n", err)
+				return
+			}
+			stars = append(stars, star)
+		}
 		
-		ratingRow := CreateButtonRow(star1, star2, star3, star4, star5)
+		ratingRow := CreateButtonRow(stars...)
 		keypad := CreateInlineKeypad([]map[string]interface{}{ratingRow})
 		
-		r.SendMessage(m.ChatID, "⭐ لطفاً به ربات امتیاز دهید:", map[string]interface{}{
-			"inline_keypad": keypad,
-		})
+		r.SendMessage(m.ChatID, "⭐ لطفاً به ربات امتیاز دهید:", WithInlineKeypad(keypad))
 	})
 
 	bot.OnCallback("btn_contact", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "📞 برای تماس با پشتیبانی:\n@Daniyel_Support", nil)
+		r.SendMessage(m.ChatID, "📞 برای تماس با پشتیبانی:\n@Daniyel_Support")
 	})
 
 	bot.OnCallback("btn_location", func(r *Robot, m *Message) {
-		r.SendLocation(m.ChatID, 35.6892, 51.3890, map[string]interface{}{
-			"text": "📍 موقعیت دفمر مرکزی",
-		})
+		r.SendLocation(m.ChatID, 35.6892, 51.3890, WithCaption("📍 موقعیت دفمر مرکزی"))
 	})
 
 	bot.OnCallback("btn_music", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "🎵 این قابلیت به زودی اضافه خواهد شد...", nil)
+		r.SendMessage(m.ChatID, "🎵 این قابلیت به زودی اضافه خواهد شد...")
 	})
 
 	bot.OnCallback("btn_photo", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "🖼 این قابلیت به زودی اضافه خواهد شد...", nil)
+		r.SendMessage(m.ChatID, "🖼 این قابلیت به زودی اضافه خواهد شد...")
 	})
 
 	bot.OnCallback("camera_btn", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "📷 دسترسی به دوربین باز شد...", nil)
+		r.SendMessage(m.ChatID, "📷 دسترسی به دوربین باز شد...")
 	})
 
 	bot.OnCallback("gallery_btn", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "🖼 دسترسی به گالری باز شد...", nil)
+		r.SendMessage(m.ChatID, "🖼 دسترسی به گالری باز شد...")
 	})
 
 	bot.OnCallback("location_btn", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "📍 موقعیت شما دریافت شد!", nil)
+		r.SendMessage(m.ChatID, "📍 موقعیت شما دریافت شد!")
 	})
 
 	bot.OnCallback("phone_btn", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "📞 شماره تلفن شما دریافت شد!", nil)
-	})
-
-	bot.OnCallback("star_1", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "😢 متاسفم که ربات رو دوست نداشتی! چه چیزی رو می‌تونی بهتر کنیم؟", nil)
+		r.SendMessage(m.ChatID, "📞 شماره تلفن شما دریافت شد!")
 	})
 
-	bot.OnCallback("star_5", func(r *Robot, m *Message) {
-		r.SendMessage(m.ChatID, "🎉 ممنون از امتیاز عالیت! خوشحالیم که ربات رو دوست داری!", nil)
+	OnCallbackData(bot, "rate", func(m *Message, data ratingData) {
+		switch data.Stars {
+		case 1:
+			m.Answer("😢 متاسفم که ربات رو دوست نداشتی! چه چیزی رو می‌تونی بهتر کنیم؟")
+		case 5:
+			m.Answer("🎉 ممنون از امتیاز عالیت! خوشحالیم که ربات رو دوست داری!")
+		default:
+			m.Answer(fmt.Sprintf("⭐ امتیاز %d ثبت شد. ممنون!", data.Stars))
+		}
 	})
 
 	fmt.Println("⏳ Bot with advanced buttons is running...")
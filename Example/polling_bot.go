@@ -61,12 +61,12 @@ func main() {
 
 	bot.OnCallback("bot_info", func(r *Robot, m *Message) {
 		fmt.Println("✅ Button bot_info clicked")
-		r.SendMessage(m.ChatID, "📊 اطلاعات ربات: این یک ربات تست است", nil)
+		r.SendMessage(m.ChatID, "📊 اطلاعات ربات: این یک ربات تست است")
 	})
 
 	bot.OnCallback("help", func(r *Robot, m *Message) {
 		fmt.Println("✅ Button help clicked")
-		r.SendMessage(m.ChatID, "ℹ️ راهنما: از /start استفاده کنید", nil)
+		r.SendMessage(m.ChatID, "ℹ️ راهنما: از /start استفاده کنید")
 	})
 
 	fmt.Println("⏳ Bot is running...")
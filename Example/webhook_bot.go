@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,16 +10,32 @@ import (
 	"time"
 )
 
+const infoTemplate = `🤖 اطلاعات ربات:
+{{with .name}}نام: {{.}}
+{{end}}{{with .username}}آیدی: @{{.}}
+{{end}}حالت: Webhook 🌐`
+
 func main() {
 	fmt.Println("🚀 Starting Rubika Bot in Webhook Mode...")
+
+	templates := NewTemplates()
+	if err := templates.Parse("info", infoTemplate); err != nil {
+		log.Fatal(err)
+	}
 	
 	bot := NewRobot("BOT_TOKEN",
 		WithWebhook("https://yourdomain.com:8080/webhook"),
 		WithTimeout(30*time.Second),
 		WithPlatform("android"),
+		WithTemplates(templates),
+		WithErrorReply("⚠️ مشکلی پیش آمد، لطفاً دوباره تلاش کنید."),
 	)
 
-	bot.OnMessage(func(r *Robot, m *Message) {
+	bot.OnError(func(ctx context.Context, update map[string]interface{}, err error) {
+		log.Printf("❌ Handler error (%v): %v", update["type"], err)
+	})
+
+	bot.OnMessage(Fallible(func(r *Robot, m *Message) error {
 		fmt.Printf("🌐 Webhook received from %s: %s\n", m.SenderID, m.Text)
 		
 		command := strings.ToLower(strings.TrimSpace(m.Text))
@@ -33,7 +50,8 @@ func main() {
 /ping - تست ارتباط
 /info - اطلاعات ربات`
 			
-			r.SendMessage(m.ChatID, welcomeMsg)
+			_, err := r.SendMessage(m.ChatID, welcomeMsg)
+			return err
 
 		case "/help", "help":
 			helpMsg := `📖 راهنمای ربات وب‌هوک:
@@ -43,34 +61,29 @@ func main() {
 • سرعت پاسخگویی بالاتر از حالت Polling است
 • مناسب برای ربات‌های پرترافیک`
 
-			r.SendMessage(m.ChatID, helpMsg)
+			_, err := r.SendMessage(m.ChatID, helpMsg)
+			return err
 
 		case "/ping", "ping":
-			r.SendMessage(m.ChatID, "🏓 Pong! Connection is working perfectly!")
+			_, err := r.SendMessage(m.ChatID, "🏓 Pong! Connection is working perfectly!")
+			return err
 
 		case "/info", "info":
 			botInfo, err := r.GetMe()
-			if err == nil {
-				infoText := "🤖 اطلاعات ربات:\n"
-				if data, ok := botInfo["data"].(map[string]interface{}); ok {
-					if botData, ok := data["bot"].(map[string]interface{}); ok {
-						if name, ok := botData["name"].(string); ok {
-							infoText += fmt.Sprintf("نام: %s\n", name)
-						}
-						if username, ok := botData["username"].(string); ok {
-							infoText += fmt.Sprintf("آیدی: @%s\n", username)
-						}
-					}
-				}
-				infoText += "حالت: Webhook 🌐"
-				r.SendMessage(m.ChatID, infoText)
+			if err != nil {
+				return err
 			}
+			data, _ := botInfo["data"].(map[string]interface{})
+			botData, _ := data["bot"].(map[string]interface{})
+			_, err = r.RenderAndSend(m.ChatID, "info", botData)
+			return err
 
 		default:
 			echoMsg := fmt.Sprintf("📨 پیام شما: %s\n\n💡 از /help برای راهنما استفاده کنید.", m.Text)
-			r.SendMessage(m.ChatID, echoMsg)
+			_, err := r.SendMessage(m.ChatID, echoMsg)
+			return err
 		}
-	})
+	}))
 
 	fmt.Println("🌐 Setting up webhook server...")
 	
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCallbackMaxLength = 64
+	defaultCallbackTagLength = 8
+)
+
+// بسته‌بندی struct در شناسه دکمه به شکل prefix:v1:v2[:sig]
+// مقادیر به ترتیب فیلدها و بدون نام ذخیره می‌شوند؛ اعداد صحیح در مبنای ۳۶.
+// امضا HMAC-SHA256 کوتاه‌شده به TagLength کاراکتر base64 است (پیش‌فرض ۸ کاراکتر = ۴۸ بیت)
+// تا در سقف ۶۴ بایتی شناسه دکمه جا بشود؛ برای امنیت بیشتر می‌توان آن را تا ۴۳ بالا برد
+type CallbackCodec struct {
+	Secret    []byte
+	MaxLength int
+	TagLength int
+}
+
+// امضای شناسه دکمه‌ها با HMAC تا کاربر نتواند داده callback را دستکاری کند؛
+// سایر تنظیمات codec دست نمی‌خورند
+func WithCallbackSecret(secret []byte) func(*Robot) {
+	return func(r *Robot) {
+		r.callbackCodec().Secret = secret
+	}
+}
+
+func (r *Robot) callbackCodec() *CallbackCodec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.CallbackCodec == nil {
+		r.CallbackCodec = &CallbackCodec{}
+	}
+	return r.CallbackCodec
+}
+
+func (c *CallbackCodec) maxLength() int {
+	if c.MaxLength <= 0 {
+		return defaultCallbackMaxLength
+	}
+	return c.MaxLength
+}
+
+func (c *CallbackCodec) tagLength() int {
+	if c.TagLength <= 0 {
+		return defaultCallbackTagLength
+	}
+	if max := base64.RawURLEncoding.EncodedLen(sha256.Size); c.TagLength > max {
+		return max
+	}
+	return c.TagLength
+}
+
+func (c *CallbackCodec) sign(data string) string {
+	mac := hmac.New(sha256.New, c.Secret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))[:c.tagLength()]
+}
+
+func callbackStruct(value reflect.Value) (reflect.Value, error) {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return value, fmt.Errorf("callback data is nil")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return value, fmt.Errorf("callback data must be a struct, got %s", value.Kind())
+	}
+	return value, nil
+}
+
+// فیلدهای exported بدون تگ cb:"-"
+func callbackFields(t reflect.Type) []int {
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("cb") == "-" {
+			continue
+		}
+		fields = append(fields, i)
+	}
+	return fields
+}
+
+var callbackEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+
+func (c *CallbackCodec) Encode(prefix string, data interface{}) (string, error) {
+	value, err := callbackStruct(reflect.ValueOf(data))
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, i := range callbackFields(value.Type()) {
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			parts = append(parts, callbackEscaper.Replace(field.String()))
+		case reflect.Bool:
+			if field.Bool() {
+				parts = append(parts, "1")
+			} else {
+				parts = append(parts, "0")
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			parts = append(parts, strconv.FormatInt(field.Int(), 36))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			parts = append(parts, strconv.FormatUint(field.Uint(), 36))
+		case reflect.Float32, reflect.Float64:
+			parts = append(parts, strconv.FormatFloat(field.Float(), 'g', -1, 64))
+		default:
+			return "", fmt.Errorf("callback field %s: unsupported type %s", value.Type().Field(i).Name, field.Type())
+		}
+	}
+
+	buttonID := prefix + ":" + strings.Join(parts, ":")
+	if len(c.Secret) > 0 {
+		buttonID += ":" + c.sign(buttonID)
+	}
+	if len(buttonID) > c.maxLength() {
+		return "", fmt.Errorf("callback data %q is %d bytes, limit is %d", buttonID, len(buttonID), c.maxLength())
+	}
+	return buttonID, nil
+}
+
+func (c *CallbackCodec) Decode(buttonID, prefix string, out interface{}) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("callback decode target must be a non-nil pointer")
+	}
+	value, err := callbackStruct(target)
+	if err != nil {
+		return err
+	}
+
+	if len(buttonID) > c.maxLength() {
+		return fmt.Errorf("callback data is %d bytes, limit is %d", len(buttonID), c.maxLength())
+	}
+	if !strings.HasPrefix(buttonID, prefix+":") {
+		return fmt.Errorf("callback data %q does not have prefix %q", buttonID, prefix)
+	}
+
+	data := buttonID
+	if len(c.Secret) > 0 {
+		cut := strings.LastIndex(data, ":")
+		if cut <= len(prefix) || !hmac.Equal([]byte(data[cut+1:]), []byte(c.sign(data[:cut]))) {
+			return fmt.Errorf("callback data %q has an invalid signature", buttonID)
+		}
+		data = data[:cut]
+	}
+
+	parts := strings.Split(data[len(prefix)+1:], ":")
+	fields := callbackFields(value.Type())
+	if len(fields) == 0 && len(parts) == 1 && parts[0] == "" {
+		return nil
+	}
+	if len(parts) != len(fields) {
+		return fmt.Errorf("callback data %q has %d fields, want %d", buttonID, len(parts), len(fields))
+	}
+
+	for n, i := range fields {
+		field := value.Field(i)
+		part := parts[n]
+		name := value.Type().Field(i).Name
+
+		switch field.Kind() {
+		case reflect.String:
+			text, err := unescapeCallback(part)
+			if err != nil {
+				return fmt.Errorf("callback field %s: %v", name, err)
+			}
+			field.SetString(text)
+		case reflect.Bool:
+			if part != "0" && part != "1" {
+				return fmt.Errorf("callback field %s: invalid bool %q", name, part)
+			}
+			field.SetBool(part == "1")
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v, err := strconv.ParseInt(part, 36, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("callback field %s: %v", name, err)
+			}
+			field.SetInt(v)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v, err := strconv.ParseUint(part, 36, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("callback field %s: %v", name, err)
+			}
+			field.SetUint(v)
+		case reflect.Float32, reflect.Float64:
+			v, err := strconv.ParseFloat(part, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("callback field %s: %v", name, err)
+			}
+			field.SetFloat(v)
+		default:
+			return fmt.Errorf("callback field %s: unsupported type %s", name, field.Type())
+		}
+	}
+	return nil
+}
+
+func unescapeCallback(text string) (string, error) {
+	if !strings.Contains(text, "%") {
+		return text, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '%' {
+			b.WriteByte(text[i])
+			continue
+		}
+		if i+2 >= len(text) {
+			return "", fmt.Errorf("invalid escape in %q", text)
+		}
+		ch, err := strconv.ParseUint(text[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", text)
+		}
+		b.WriteByte(byte(ch))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// ساخت دکمه شیشه‌ای با داده ساختاریافته
+func (r *Robot) CallbackButton(text, prefix string, data interface{}, buttonType ...string) (map[string]interface{}, error) {
+	buttonID, err := r.callbackCodec().Encode(prefix, data)
+	if err != nil {
+		return nil, err
+	}
+	return CreateInlineButton(text, buttonID, buttonType...), nil
+}
+
+// هندلر دکمه‌هایی که با CallbackButton و همین prefix ساخته شده‌اند
+func OnCallbackData[T any](r *Robot, prefix string, handler func(*Message, T)) {
+	r.OnCallbackPrefix(prefix+":", func(r *Robot, m *Message) {
+		var data T
+		if err := r.callbackCodec().Decode(m.ButtonID(), prefix, &data); err != nil {
+			r.reportError(m, fmt.Errorf("callback data: %v", err))
+			return
+		}
+		handler(m, data)
+	})
+}
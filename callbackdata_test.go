@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+type testCallbackData struct {
+	OrderID int64
+	Stars   int
+	Note    string
+	Paid    bool
+	Skip    string `cb:"-"`
+}
+
+func TestCallbackCodecRoundTrip(t *testing.T) {
+	codecs := map[string]*CallbackCodec{
+		"plain":  {},
+		"signed": {Secret: []byte("secret")},
+		"tag16":  {Secret: []byte("secret"), TagLength: 16},
+	}
+	values := []testCallbackData{
+		{OrderID: 123456, Stars: 5, Note: "a:b%c", Paid: true},
+		{OrderID: -1, Note: "نظر"},
+		{},
+	}
+
+	for name, codec := range codecs {
+		for _, want := range values {
+			buttonID, err := codec.Encode("rate", want)
+			if err != nil {
+				t.Fatalf("%s: Encode(%+v) error: %v", name, want, err)
+			}
+			if !strings.HasPrefix(buttonID, "rate:") {
+				t.Errorf("%s: buttonID %q has no prefix", name, buttonID)
+			}
+
+			var got testCallbackData
+			if err := codec.Decode(buttonID, "rate", &got); err != nil {
+				t.Fatalf("%s: Decode(%q) error: %v", name, buttonID, err)
+			}
+			if got != want {
+				t.Errorf("%s: Decode(%q) = %+v, want %+v", name, buttonID, got, want)
+			}
+		}
+	}
+}
+
+func TestCallbackCodecTagLength(t *testing.T) {
+	for _, tt := range []struct {
+		tagLength int
+		want      int
+	}{
+		{0, 8},
+		{16, 16},
+		{100, 43},
+	} {
+		codec := &CallbackCodec{Secret: []byte("secret"), TagLength: tt.tagLength}
+		if got := len(codec.sign("rate:1")); got != tt.want {
+			t.Errorf("TagLength %d: signature length = %d, want %d", tt.tagLength, got, tt.want)
+		}
+	}
+}
+
+func TestCallbackCodecRejectsTampering(t *testing.T) {
+	codec := &CallbackCodec{Secret: []byte("secret")}
+	buttonID, err := codec.Encode("rate", testCallbackData{OrderID: 1, Stars: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data testCallbackData
+	for _, forged := range []string{
+		strings.Replace(buttonID, ":5:", ":1:", 1),
+		"rate:" + buttonID[strings.LastIndex(buttonID, ":")+1:],
+		"rate",
+		"other:1:5::0",
+	} {
+		if err := codec.Decode(forged, "rate", &data); err == nil {
+			t.Errorf("Decode(%q) expected error", forged)
+		}
+	}
+}
+
+func TestCallbackCodecMaxLength(t *testing.T) {
+	codec := &CallbackCodec{}
+	if _, err := codec.Encode("rate", testCallbackData{Note: strings.Repeat("x", 100)}); err == nil {
+		t.Error("expected error for button ID longer than 64 bytes")
+	}
+}
+
+func TestWithCallbackSecretKeepsCodec(t *testing.T) {
+	r := NewRobot("token")
+	r.CallbackCodec = &CallbackCodec{MaxLength: 128, TagLength: 12}
+	WithCallbackSecret([]byte("secret"))(r)
+	if r.CallbackCodec.MaxLength != 128 || r.CallbackCodec.TagLength != 12 || string(r.CallbackCodec.Secret) != "secret" {
+		t.Errorf("codec = %+v", r.CallbackCodec)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type CallbackOption func(*CallbackHandler)
+
+// اولویت صریح؛ هندلر با اولویت بالاتر قبل از بقیه بررسی می‌شود
+func WithPriority(priority int) CallbackOption {
+	return func(h *CallbackHandler) {
+		h.Priority = priority
+	}
+}
+
+// هندلر برای دکمه‌هایی که شناسه‌شان با الگو مطابقت دارد؛ گروه‌ها در m.Matches و m.Params قرار می‌گیرند
+func (r *Robot) OnCallbackRegex(pattern string, handler func(*Robot, *Message), options ...CallbackOption) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid callback pattern %q: %v", pattern, err)
+	}
+
+	r.addCallbackHandler(CallbackHandler{
+		ButtonID: pattern,
+		Pattern:  re,
+		Handler:  handler,
+	}, options)
+	return nil
+}
+
+func (r *Robot) addCallbackHandler(handler CallbackHandler, options []CallbackOption) {
+	for _, option := range options {
+		option(&handler)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.CallbackHandlers = append(r.CallbackHandlers, handler)
+	sort.SliceStable(r.CallbackHandlers, func(i, j int) bool {
+		a, b := r.CallbackHandlers[i], r.CallbackHandlers[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.rank() != b.rank() {
+			return a.rank() > b.rank()
+		}
+		return a.Prefix && b.Prefix && len(a.ButtonID) > len(b.ButtonID)
+	})
+}
+
+// میزان خاص بودن: شناسه دقیق، پیشوند، الگو، همه دکمه‌ها
+func (h CallbackHandler) rank() int {
+	switch {
+	case h.Pattern != nil:
+		return 1
+	case h.ButtonID == "":
+		return 0
+	case h.Prefix:
+		return 2
+	}
+	return 3
+}
+
+func (h CallbackHandler) match(buttonID string) ([]string, bool) {
+	switch {
+	case h.Pattern != nil:
+		matches := h.Pattern.FindStringSubmatch(buttonID)
+		return matches, matches != nil
+	case h.ButtonID == "":
+		return nil, true
+	case h.Prefix:
+		return nil, strings.HasPrefix(buttonID, h.ButtonID)
+	}
+	return nil, h.ButtonID == buttonID
+}
+
+// هندلرها از قبل به ترتیب اولویت و خاص بودن مرتب شده‌اند؛ اولین تطبیق انتخاب می‌شود
+func (r *Robot) findCallbackHandler(m *Message, buttonID string) *CallbackHandler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.CallbackHandlers {
+		handler := r.CallbackHandlers[i]
+		matches, ok := handler.match(buttonID)
+		if !ok {
+			continue
+		}
+
+		if handler.Pattern != nil {
+			m.Matches = matches
+			m.Params = make(map[string]string)
+			for n, name := range handler.Pattern.SubexpNames() {
+				if name != "" {
+					m.Params[name] = matches[n]
+				}
+			}
+		}
+		return &handler
+	}
+	return nil
+}
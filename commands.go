@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+type CommandHandler struct {
+	Command     string
+	Description string
+	Handler     func(*Robot, *Message)
+}
+
+func WithAutoCommands() func(*Robot) {
+	return func(r *Robot) {
+		r.AutoCommands = true
+	}
+}
+
+func (r *Robot) SetCommands(commands []BotCommand) (map[string]interface{}, error) {
+	botCommands := make([]map[string]interface{}, 0, len(commands))
+	for _, cmd := range commands {
+		name := strings.TrimPrefix(strings.TrimSpace(cmd.Command), "/")
+		if name == "" {
+			return nil, fmt.Errorf("command name is empty")
+		}
+		botCommands = append(botCommands, map[string]interface{}{
+			"command":     name,
+			"description": cmd.Description,
+		})
+	}
+
+	return r.post("setCommands", map[string]interface{}{
+		"bot_commands": botCommands,
+	})
+}
+
+// ثبت دستور به همراه توضیحات برای منوی دستورات
+func (r *Robot) OnCommand(command, description string, handler func(*Robot, *Message)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.CommandHandlers = append(r.CommandHandlers, CommandHandler{
+		Command:     strings.TrimPrefix(strings.TrimSpace(command), "/"),
+		Description: description,
+		Handler:     handler,
+	})
+}
+
+func (r *Robot) Commands() []BotCommand {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	commands := make([]BotCommand, 0, len(r.CommandHandlers))
+	for _, handler := range r.CommandHandlers {
+		if handler.Description == "" {
+			continue
+		}
+		commands = append(commands, BotCommand{
+			Command:     handler.Command,
+			Description: handler.Description,
+		})
+	}
+	return commands
+}
+
+func (r *Robot) publishCommands() {
+	if !r.AutoCommands {
+		return
+	}
+
+	commands := r.Commands()
+	if len(commands) == 0 {
+		return
+	}
+
+	if _, err := r.SetCommands(commands); err != nil {
+		fmt.Printf("❌ Error setting commands: %v\n", err)
+		return
+	}
+	fmt.Printf("📋 %d commands published\n", len(commands))
+}
+
+// پیدا کردن هندلر دستور برای متن پیام، مثل "/start" یا "/start arg"
+func (r *Robot) findCommandHandler(text string) *CommandHandler {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return nil
+	}
+
+	name := strings.TrimPrefix(text, "/")
+	if i := strings.IndexAny(name, " \n\t"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.CommandHandlers {
+		if r.CommandHandlers[i].Command == name {
+			handler := r.CommandHandlers[i]
+			return &handler
+		}
+	}
+	return nil
+}
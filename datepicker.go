@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Calendar int
+
+const (
+	CalendarGregorian Calendar = iota
+	CalendarJalali
+)
+
+const datePickerPrefix = "dp:"
+
+var (
+	jalaliWeekdayShort    = []string{"ش", "ی", "د", "س", "چ", "پ", "ج"}
+	gregorianWeekdayShort = []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+)
+
+// تقویم شیشه‌ای برای انتخاب تاریخ (و در صورت نیاز ساعت)
+type DatePicker struct {
+	ID         string
+	Calendar   Calendar
+	WithTime   bool
+	MinuteStep int
+	Min        time.Time
+	Max        time.Time
+	Location   *time.Location
+	OnSelect   func(*Robot, *Message, time.Time)
+
+	bot *Robot
+}
+
+// ساخت تقویم و ثبت هندلر دکمه‌های آن با پیشوند dp:<id>:؛
+// هر پیام ارسال‌شده یک nonce در شناسه دکمه‌ها دارد تا چند تقویم در یک چت با هم تداخل نکنند
+func (r *Robot) NewDatePicker(id string, calendar Calendar, onSelect func(*Robot, *Message, time.Time)) *DatePicker {
+	picker := &DatePicker{
+		ID:         id,
+		Calendar:   calendar,
+		MinuteStep: 15,
+		OnSelect:   onSelect,
+		bot:        r,
+	}
+	r.OnCallbackPrefix(picker.prefix(), picker.handle)
+	return picker
+}
+
+func (p *DatePicker) prefix() string {
+	return datePickerPrefix + p.ID + ":"
+}
+
+func (p *DatePicker) location() *time.Location {
+	if p.Location != nil {
+		return p.Location
+	}
+	return time.Local
+}
+
+func (p *DatePicker) storageKey(chatID, nonce string) string {
+	return "datepicker:" + p.ID + ":" + chatID + ":" + nonce
+}
+
+// ارسال پیام همراه با تقویم ماه داده‌شده
+func (p *DatePicker) Send(chatID, text string, month time.Time, options ...SendOption) (*SentMessage, error) {
+	nonce := newKeypadNonce()
+	return p.bot.sendKeypad(chatID, p.storageKey(chatID, nonce), text, p.keypad(nonce, month), options...)
+}
+
+func (p *DatePicker) digits(n int, width int) string {
+	text := fmt.Sprintf("%0*d", width, n)
+	if p.Calendar == CalendarJalali {
+		return ToPersianDigits(text)
+	}
+	return text
+}
+
+func (p *DatePicker) monthStart(t time.Time) time.Time {
+	t = t.In(p.location())
+	if p.Calendar == CalendarJalali {
+		d := ToJalali(t)
+		return JalaliDate{Year: d.Year, Month: d.Month, Day: 1}.Time(p.location())
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, p.location())
+}
+
+func (p *DatePicker) addMonths(start time.Time, months int) time.Time {
+	if p.Calendar == CalendarJalali {
+		return ToJalali(start).AddMonths(months).Time(p.location())
+	}
+	return start.AddDate(0, months, 0)
+}
+
+func (p *DatePicker) monthTitle(start time.Time) string {
+	if p.Calendar == CalendarJalali {
+		d := ToJalali(start)
+		return JalaliMonthNames[d.Month-1] + " " + p.digits(d.Year, 0)
+	}
+	return start.Format("January 2006")
+}
+
+func (p *DatePicker) dateLabel(t time.Time) string {
+	if p.Calendar == CalendarJalali {
+		return FormatJalaliPersian(t, "D MMMM YYYY")
+	}
+	return t.Format("2 Jan 2006")
+}
+
+func (p *DatePicker) dayOf(t time.Time) time.Time {
+	t = t.In(p.location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, p.location())
+}
+
+func (p *DatePicker) allowed(day time.Time) bool {
+	if !p.Min.IsZero() && day.Before(p.dayOf(p.Min)) {
+		return false
+	}
+	if !p.Max.IsZero() && day.After(p.dayOf(p.Max)) {
+		return false
+	}
+	return true
+}
+
+// بعد از انتخاب ساعت، خود زمان (نه فقط روز) باید در بازه Min و Max باشد
+func (p *DatePicker) allowedTime(t time.Time) bool {
+	if !p.Min.IsZero() && t.Before(p.Min) {
+		return false
+	}
+	if !p.Max.IsZero() && t.After(p.Max) {
+		return false
+	}
+	return true
+}
+
+// دکمه‌های بی‌عمل (عنوان، خانه خالی) هم شناسه یکتا لازم دارند
+type noopButtons struct {
+	picker *DatePicker
+	nonce  string
+	count  int
+}
+
+func (n *noopButtons) button(text string) map[string]interface{} {
+	n.count++
+	return CreateInlineButton(text, n.picker.prefix()+n.nonce+":x:"+strconv.Itoa(n.count))
+}
+
+func (p *DatePicker) button(nonce, text, action, value string) map[string]interface{} {
+	return CreateInlineButton(text, p.prefix()+nonce+":"+action+":"+value)
+}
+
+// کیبورد شیشه‌ای ماهی که month در آن قرار دارد؛ فقط از طریق Send ارسال می‌شود
+// تا شناسه پیام برای ویرایش در جا ذخیره شده باشد
+func (p *DatePicker) keypad(nonce string, month time.Time) map[string]interface{} {
+	start := p.monthStart(month)
+	prev := p.addMonths(start, -1)
+	next := p.addMonths(start, 1)
+	noop := &noopButtons{picker: p, nonce: nonce}
+
+	navPrev, navNext := noop.button(" "), noop.button(" ")
+	if p.Min.IsZero() || p.dayOf(p.Min).Before(start) {
+		navPrev = p.button(nonce, "‹", "m", prev.Format("20060102"))
+	}
+	if p.Max.IsZero() || !next.After(p.dayOf(p.Max)) {
+		navNext = p.button(nonce, "›", "m", next.Format("20060102"))
+	}
+	rows := []map[string]interface{}{
+		CreateButtonRow(navPrev, noop.button(p.monthTitle(start)), navNext),
+	}
+
+	weekdays := gregorianWeekdayShort
+	offset := (int(start.Weekday()) + 6) % 7
+	if p.Calendar == CalendarJalali {
+		weekdays = jalaliWeekdayShort
+		offset = (int(start.Weekday()) + 1) % 7
+	}
+	var header []map[string]interface{}
+	for _, name := range weekdays {
+		header = append(header, noop.button(name))
+	}
+	rows = append(rows, CreateButtonRow(header...))
+
+	var week []map[string]interface{}
+	for i := 0; i < offset; i++ {
+		week = append(week, noop.button(" "))
+	}
+	for day := start; day.Before(next); day = day.AddDate(0, 0, 1) {
+		number := day.Day()
+		if p.Calendar == CalendarJalali {
+			number = ToJalali(day).Day
+		}
+
+		action := "d"
+		if p.WithTime {
+			action = "h"
+		}
+		if p.allowed(day) {
+			week = append(week, p.button(nonce, p.digits(number, 0), action, day.Format("20060102")))
+		} else {
+			week = append(week, noop.button("·"))
+		}
+
+		if len(week) == 7 {
+			rows = append(rows, CreateButtonRow(week...))
+			week = nil
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, noop.button(" "))
+		}
+		rows = append(rows, CreateButtonRow(week...))
+	}
+
+	return CreateInlineKeypad(rows)
+}
+
+func (p *DatePicker) hoursKeypad(nonce string, day time.Time) map[string]interface{} {
+	noop := &noopButtons{picker: p, nonce: nonce}
+	rows := []map[string]interface{}{
+		CreateButtonRow(
+			p.button(nonce, "‹", "m", day.Format("20060102")),
+			noop.button(p.dateLabel(day)),
+		),
+	}
+
+	var row []map[string]interface{}
+	for hour := 0; hour < 24; hour++ {
+		start := time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, p.location())
+		// ساعتی که هیچ دقیقه‌اش در بازه نیست قابل انتخاب نیست
+		if p.allowedTime(start) || p.allowedTime(start.Add(time.Hour-time.Minute)) {
+			row = append(row, p.button(nonce, p.digits(hour, 2), "H", start.Format("2006010215")))
+		} else {
+			row = append(row, noop.button("·"))
+		}
+		if len(row) == 6 {
+			rows = append(rows, CreateButtonRow(row...))
+			row = nil
+		}
+	}
+	return CreateInlineKeypad(rows)
+}
+
+func (p *DatePicker) minutesKeypad(nonce string, hour time.Time) map[string]interface{} {
+	step := p.MinuteStep
+	if step <= 0 || step > 60 {
+		step = 15
+	}
+
+	noop := &noopButtons{picker: p, nonce: nonce}
+	rows := []map[string]interface{}{
+		CreateButtonRow(
+			p.button(nonce, "‹", "h", hour.Format("20060102")),
+			noop.button(p.dateLabel(hour)),
+		),
+	}
+
+	var row []map[string]interface{}
+	for minute := 0; minute < 60; minute += step {
+		label := p.digits(hour.Hour(), 2) + ":" + p.digits(minute, 2)
+		if p.allowedTime(hour.Add(time.Duration(minute) * time.Minute)) {
+			row = append(row, p.button(nonce, label, "t", hour.Format("2006010215")+fmt.Sprintf("%02d", minute)))
+		} else {
+			row = append(row, noop.button("·"))
+		}
+		if len(row) == 4 {
+			rows = append(rows, CreateButtonRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, CreateButtonRow(row...))
+	}
+	return CreateInlineKeypad(rows)
+}
+
+func (p *DatePicker) show(m *Message, nonce string, keypad map[string]interface{}) error {
+	return p.bot.replaceKeypad(m.ChatID, p.storageKey(m.ChatID, nonce), "📅", keypad)
+}
+
+func (p *DatePicker) handle(r *Robot, m *Message) {
+	parts := strings.SplitN(strings.TrimPrefix(m.ButtonID(), p.prefix()), ":", 3)
+	if len(parts) != 3 {
+		return
+	}
+	nonce, action, value := parts[0], parts[1], parts[2]
+
+	layouts := map[string]string{
+		"m": "20060102",
+		"h": "20060102",
+		"d": "20060102",
+		"H": "2006010215",
+		"t": "200601021504",
+	}
+	layout, ok := layouts[action]
+	if !ok {
+		return
+	}
+	t, err := time.ParseInLocation(layout, value, p.location())
+	if err != nil {
+		return
+	}
+
+	switch action {
+	case "m":
+		err = p.show(m, nonce, p.keypad(nonce, t))
+	case "h":
+		err = p.show(m, nonce, p.hoursKeypad(nonce, t))
+	case "H":
+		err = p.show(m, nonce, p.minutesKeypad(nonce, t))
+	case "d", "t":
+		if action == "d" && !p.allowed(p.dayOf(t)) || action == "t" && !p.allowedTime(t) {
+			return
+		}
+		// انتخاب تمام شد و شناسه پیام تقویم دیگر لازم نیست
+		if err := r.storage().Delete(p.storageKey(m.ChatID, nonce)); err != nil {
+			r.reportError(m, fmt.Errorf("date picker %q: %v", p.ID, err))
+		}
+		if p.OnSelect != nil {
+			p.OnSelect(r, m, t)
+		}
+	}
+	if err != nil {
+		r.reportError(m, fmt.Errorf("date picker %q: %v", p.ID, err))
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// اطلاعات فایل؛ Size صفر یعنی حجم نامشخص
+type FileInfo struct {
+	FileID      string
+	DownloadURL string
+	FileName    string
+	Size        int64
+	MimeType    string
+}
+
+type DownloadResult struct {
+	Size   int64
+	SHA256 string
+}
+
+type DownloadOption func(*downloadConfig)
+
+type downloadConfig struct {
+	maxSize  int64
+	checksum string
+}
+
+// حداکثر حجم مجاز فایل؛ دانلود بزرگ‌تر از این مقدار متوقف می‌شود
+func WithMaxSize(maxSize int64) DownloadOption {
+	return func(c *downloadConfig) {
+		c.maxSize = maxSize
+	}
+}
+
+// هش SHA-256 مورد انتظار به صورت hex؛ در صورت عدم تطابق خطا برگردانده می‌شود
+func WithChecksum(sha256Hex string) DownloadOption {
+	return func(c *downloadConfig) {
+		c.checksum = strings.ToLower(sha256Hex)
+	}
+}
+
+func newDownloadConfig(options []DownloadOption) *downloadConfig {
+	config := &downloadConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+func (r *Robot) GetFile(fileID string) (*FileInfo, error) {
+	result, err := r.post("getFile", map[string]interface{}{
+		"file_id": fileID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := result["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid getFile response")
+	}
+
+	downloadURL, ok := data["download_url"].(string)
+	if !ok || downloadURL == "" {
+		return nil, fmt.Errorf("download URL not found")
+	}
+
+	info := &FileInfo{
+		FileID:      fileID,
+		DownloadURL: downloadURL,
+	}
+	if _, ok := data["file_id"]; !ok {
+		data["file_id"] = fileID
+	}
+	if file := parseFile(data); file != nil {
+		info.FileName = file.FileName
+		info.Size = file.Size
+		info.MimeType = file.MimeType
+	}
+
+	if info.FileName == "" || info.Size == 0 || info.MimeType == "" {
+		r.headFileInfo(info)
+	}
+	return info, nil
+}
+
+// تکمیل نام، حجم و نوع فایل از هدرهای پاسخ HEAD؛ خطا نادیده گرفته می‌شود
+func (r *Robot) headFileInfo(info *FileInfo) {
+	req, err := http.NewRequest("HEAD", info.DownloadURL, nil)
+	if err != nil {
+		return
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	if info.Size == 0 && resp.ContentLength > 0 {
+		info.Size = resp.ContentLength
+	}
+	if info.MimeType == "" {
+		if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+			info.MimeType = mediaType
+		}
+	}
+	if info.FileName == "" {
+		if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+			info.FileName = params["filename"]
+		}
+	}
+	if info.FileName == "" {
+		if u, err := url.Parse(info.DownloadURL); err == nil {
+			if name := path.Base(u.Path); name != "/" && name != "." {
+				info.FileName = name
+			}
+		}
+	}
+	if info.MimeType == "" && info.FileName != "" {
+		info.MimeType = mime.TypeByExtension(path.Ext(info.FileName))
+	}
+}
+
+// پاسخ 416؛ Size حجم کامل فایل از هدر Content-Range (یا -1 اگر نامشخص باشد)
+type rangeNotSatisfiableError struct {
+	Size int64
+}
+
+func (e *rangeNotSatisfiableError) Error() string {
+	return "requested range not satisfiable"
+}
+
+// شروع دانلود از offset مشخص؛ partial نشان می‌دهد سرور Range را پذیرفته است
+func (r *Robot) openDownload(ctx context.Context, downloadURL string, offset int64) (*http.Response, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := r.transferClient().Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, false, nil
+	case http.StatusPartialContent:
+		return resp, offset > 0, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		size := int64(-1)
+		if total := strings.TrimPrefix(resp.Header.Get("Content-Range"), "bytes */"); total != resp.Header.Get("Content-Range") {
+			if n, err := strconv.ParseInt(total, 10, 64); err == nil {
+				size = n
+			}
+		}
+		return nil, false, &rangeNotSatisfiableError{Size: size}
+	}
+
+	resp.Body.Close()
+	return nil, false, fmt.Errorf("download failed: %s", resp.Status)
+}
+
+func copyLimited(w io.Writer, body io.Reader, written int64, config *downloadConfig) (int64, error) {
+	if config.maxSize <= 0 {
+		return io.Copy(w, body)
+	}
+
+	remaining := config.maxSize - written
+	n, err := io.Copy(w, io.LimitReader(body, remaining+1))
+	if err != nil {
+		return n, err
+	}
+	if n > remaining {
+		return n, fmt.Errorf("file exceeds max size of %d bytes", config.maxSize)
+	}
+	return n, nil
+}
+
+func verifyChecksum(sum string, config *downloadConfig) error {
+	if config.checksum != "" && config.checksum != sum {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", config.checksum, sum)
+	}
+	return nil
+}
+
+// دانلود جریانی فایل در هر io.Writer
+func (r *Robot) DownloadFile(ctx context.Context, fileID string, w io.Writer, options ...DownloadOption) (*DownloadResult, error) {
+	config := newDownloadConfig(options)
+
+	info, err := r.GetFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, guard, cancel := r.transferContext(ctx)
+	defer cancel()
+
+	resp, _, err := r.openDownload(ctx, info.DownloadURL, 0)
+	if err != nil {
+		return nil, guard.err(err)
+	}
+	defer resp.Body.Close()
+
+	if config.maxSize > 0 && resp.ContentLength > config.maxSize {
+		return nil, fmt.Errorf("file exceeds max size of %d bytes", config.maxSize)
+	}
+
+	hasher := sha256.New()
+	n, err := copyLimited(io.MultiWriter(w, hasher), guard.reader(resp.Body), 0, config)
+	if err != nil {
+		return nil, guard.err(err)
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	if err := verifyChecksum(sum, config); err != nil {
+		return nil, err
+	}
+
+	return &DownloadResult{Size: n, SHA256: sum}, nil
+}
+
+// فایل .part به file_id گره خورده تا دانلود ناتمام فایل دیگری ادامه داده نشود
+func partFilePath(path, fileID string) string {
+	sum := sha256.Sum256([]byte(fileID))
+	return path + "." + hex.EncodeToString(sum[:6]) + ".part"
+}
+
+// دانلود در مسیر مشخص؛ دانلود ناتمام در فایل .part نگه داشته می‌شود و دفعه بعد ادامه پیدا می‌کند
+func (r *Robot) DownloadToPath(ctx context.Context, fileID, path string, options ...DownloadOption) (*DownloadResult, error) {
+	config := newDownloadConfig(options)
+	partPath := partFilePath(path, fileID)
+
+	info, err := r.GetFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, guard, cancel := r.transferContext(ctx)
+	defer cancel()
+
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// هش بخش دانلودشده قبلی برای ادامه محاسبه checksum
+	hasher := sha256.New()
+	offset, err := io.Copy(hasher, file)
+	if err != nil {
+		return nil, err
+	}
+
+	restart := func() error {
+		if err := file.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		hasher.Reset()
+		offset = 0
+		return nil
+	}
+
+	// .part بزرگ‌تر از فایل اصلی متعلق به دانلود دیگری است
+	if info.Size > 0 && offset > info.Size {
+		if err := restart(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, partial, err := r.openDownload(ctx, info.DownloadURL, offset)
+	var rangeErr *rangeNotSatisfiableError
+	if errors.As(err, &rangeErr) {
+		if rangeErr.Size == offset {
+			// فایل .part قبلاً کامل شده است
+			resp, partial, err = nil, true, nil
+		} else {
+			if err := restart(); err != nil {
+				return nil, err
+			}
+			resp, partial, err = r.openDownload(ctx, info.DownloadURL, 0)
+		}
+	}
+	if err != nil {
+		return nil, guard.err(err)
+	}
+
+	written := offset
+	if resp != nil {
+		defer resp.Body.Close()
+
+		if !partial {
+			// سرور از Range پشتیبانی نمی‌کند؛ از ابتدا دانلود می‌کنیم
+			if err := file.Truncate(0); err != nil {
+				return nil, err
+			}
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			hasher.Reset()
+			written = 0
+		}
+
+		if config.maxSize > 0 && resp.ContentLength > config.maxSize-written {
+			return nil, fmt.Errorf("file exceeds max size of %d bytes", config.maxSize)
+		}
+
+		n, err := copyLimited(io.MultiWriter(file, hasher), guard.reader(resp.Body), written, config)
+		written += n
+		if err != nil {
+			return nil, guard.err(err)
+		}
+	}
+
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	if err := verifyChecksum(sum, config); err != nil {
+		file.Close()
+		os.Remove(partPath)
+		return nil, err
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(partPath, path); err != nil {
+		return nil, err
+	}
+
+	return &DownloadResult{Size: written, SHA256: sum}, nil
+}
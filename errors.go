@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+type messageContextKey struct{}
+
+// خطای ناشی از panic در هندلر به همراه stack
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// هندلر خطاهای هندلرها و panicها؛ update همان به‌روزرسانی خام دریافتی است
+func (r *Robot) OnError(handler func(ctx context.Context, update map[string]interface{}, err error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ErrorHandler = handler
+}
+
+// ارسال پیام به کاربر وقتی هندلر با خطا یا panic تمام شود
+func WithErrorReply(text string) func(*Robot) {
+	return func(r *Robot) {
+		r.ErrorReply = text
+	}
+}
+
+// تبدیل هندلری که خطا برمی‌گرداند به امضای معمول هندلرها؛ خطا به OnError می‌رسد
+func Fallible(handler func(*Robot, *Message) error) func(*Robot, *Message) {
+	return func(r *Robot, m *Message) {
+		if err := handler(r, m); err != nil {
+			r.reportError(m, err)
+		}
+	}
+}
+
+// پیامی که خطا هنگام پردازش آن رخ داده (در صورت وجود)
+func MessageFromContext(ctx context.Context) *Message {
+	m, _ := ctx.Value(messageContextKey{}).(*Message)
+	return m
+}
+
+func (r *Robot) reportError(m *Message, err error) {
+	r.handleError(m, m.update, err)
+}
+
+func (r *Robot) handleError(m *Message, update map[string]interface{}, err error) {
+	// panic در OnError یا پیام خطا فقط لاگ می‌شود تا ربات از کار نیفتد
+	defer func() {
+		if p := recover(); p != nil {
+			fmt.Printf("❌ Panic in error handler: %v (original error: %v)\n%s\n", p, err, debug.Stack())
+		}
+	}()
+
+	r.mu.Lock()
+	handler := r.ErrorHandler
+	reply := r.ErrorReply
+	r.mu.Unlock()
+
+	if handler != nil {
+		ctx := context.Background()
+		if m != nil {
+			ctx = context.WithValue(ctx, messageContextKey{}, m)
+		}
+		handler(ctx, update, err)
+	} else if panicErr, ok := err.(*PanicError); ok {
+		fmt.Printf("❌ Handler panic: %v\n%s\n", panicErr.Value, panicErr.Stack)
+	} else {
+		fmt.Printf("❌ Handler error: %v\n", err)
+	}
+
+	if reply != "" && m != nil && m.ChatID != "" {
+		if _, err := r.SendMessage(m.ChatID, reply); err != nil {
+			fmt.Printf("❌ Error sending error reply: %v\n", err)
+		}
+	}
+}
+
+// اجرای هندلر در goroutine جدا؛ panic گرفته می‌شود تا کل ربات از کار نیفتد
+func (r *Robot) dispatch(m *Message, update map[string]interface{}, fn func()) {
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				r.handleError(m, update, &PanicError{Value: p, Stack: debug.Stack()})
+			}
+		}()
+		fn()
+	}()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"mime"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// اطلاعات فایل پیوست‌شده به پیام دریافتی
+type File struct {
+	FileID   string
+	FileName string
+	Size     int64
+	MimeType string
+}
+
+func parseFile(raw map[string]interface{}) *File {
+	fileID, _ := raw["file_id"].(string)
+	if fileID == "" {
+		return nil
+	}
+
+	file := &File{FileID: fileID}
+	file.FileName, _ = raw["file_name"].(string)
+
+	switch size := raw["size"].(type) {
+	case float64:
+		file.Size = int64(size)
+	case string:
+		file.Size, _ = strconv.ParseInt(size, 10, 64)
+	}
+
+	file.MimeType, _ = raw["mime"].(string)
+	if file.MimeType == "" && file.FileName != "" {
+		file.MimeType = mime.TypeByExtension(filepath.Ext(file.FileName))
+	}
+
+	return file
+}
+
+// زمان پیام در روبیکا به صورت ثانیه یونیکس (عدد یا رشته) ارسال می‌شود
+func parseMessageTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0)
+	case string:
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// ارسال فایلی که قبلاً آپلود شده (مثلاً از پیام کاربر) بدون آپلود دوباره
+func (r *Robot) SendFileByID(chatID, fileID string, options ...SendOption) (*SentMessage, error) {
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+		"file_id": fileID,
+	}
+
+	if err := r.applySendOptions(payload, options); err != nil {
+		return nil, err
+	}
+
+	return r.send("sendFile", chatID, payload)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// کش file_id بر اساس SHA-256 محتوا و نوع مدیا؛ داده‌ها در Storage ربات نگه‌داری می‌شوند
+func WithFileCache() func(*Robot) {
+	return func(r *Robot) {
+		r.FileCache = true
+	}
+}
+
+func fileCacheKey(hash, mediaType string) string {
+	return fmt.Sprintf("file_id:%s:%s", mediaType, hash)
+}
+
+// محاسبه هش محتوا و برگرداندن خواننده به ابتدای فایل؛ ورودی‌های غیرقابل seek کش نمی‌شوند
+func hashInput(input InputFile) (string, bool, error) {
+	seeker, ok := input.Reader.(io.ReadSeeker)
+	if !ok {
+		return "", false, nil
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", false, nil
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, seeker); err != nil {
+		return "", false, err
+	}
+
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return "", false, err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), true, nil
+}
+
+func isAPIError(result map[string]interface{}) bool {
+	status, ok := result["status"].(string)
+	return ok && status != "OK"
+}
+
+// سرور ورودی نامعتبر (مثلاً file_id منقضی‌شده) را با INVALID_INPUT رد می‌کند؛
+// خطاهای دیگر مثل محدودیت تعداد درخواست دلیلی برای آپلود دوباره نیستند
+func isFileIDRejected(result map[string]interface{}) bool {
+	status, _ := result["status"].(string)
+	return status == "INVALID_INPUT"
+}
+
+// کلید کش ورودی؛ خالی یعنی کش خاموش است یا ورودی قابل seek نیست
+func (r *Robot) fileCacheKeyFor(input InputFile, mediaType string) (string, error) {
+	if !r.FileCache {
+		return "", nil
+	}
+	hash, cacheable, err := hashInput(input)
+	if err != nil || !cacheable {
+		return "", err
+	}
+	return fileCacheKey(hash, mediaType), nil
+}
+
+func (r *Robot) cachedFileID(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	fileID, ok, err := r.storage().Get(key)
+	return fileID, err == nil && ok && fileID != ""
+}
+
+// آپلود و نگه‌داشتن file_id جدید در کش
+func (r *Robot) uploadAndCache(ctx context.Context, key string, input InputFile, mediaType string, options ...UploadOption) (string, error) {
+	fileID, err := r.UploadReader(ctx, input.Reader, input.Name, mediaType, input.Size, options...)
+	if err != nil {
+		return "", err
+	}
+
+	if key != "" {
+		if err := r.storage().Set(key, fileID); err != nil {
+			fmt.Printf("❌ Error caching file ID: %v\n", err)
+		}
+	}
+	return fileID, nil
+}
+
+// ارسال با file_id کش‌شده؛ accepted=false یعنی سرور file_id را رد کرد، کلید کش حذف شد و باید دوباره آپلود شود
+func (r *Robot) sendCachedFileID(chatID, key, fileID string, payload map[string]interface{}) (sent *SentMessage, accepted bool, err error) {
+	payload["file_id"] = fileID
+	result, err := r.post("sendFile", payload)
+	if err != nil {
+		return nil, true, err
+	}
+	if isFileIDRejected(result) {
+		r.storage().Delete(key)
+		return nil, false, nil
+	}
+
+	sent, err = parseSentMessage(chatID, result)
+	if err != nil {
+		return nil, true, err
+	}
+	if sent.FileID == "" {
+		sent.FileID = fileID
+	}
+	return sent, true, nil
+}
+
+func (r *Robot) sendFileCached(ctx context.Context, chatID string, file interface{}, mediaType string, payload map[string]interface{}, options ...UploadOption) (*SentMessage, error) {
+	input, closeFn, err := openInputFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+
+	key, err := r.fileCacheKeyFor(input, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileID, ok := r.cachedFileID(key); ok {
+		if sent, accepted, err := r.sendCachedFileID(chatID, key, fileID, payload); accepted {
+			return sent, err
+		}
+	}
+
+	fileID, err := r.uploadAndCache(ctx, key, input, mediaType, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	payload["file_id"] = fileID
+	return r.send("sendFile", chatID, payload)
+}
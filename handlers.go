@@ -0,0 +1,202 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ChatTypeUser    = "User"
+	ChatTypeGroup   = "Group"
+	ChatTypeChannel = "Channel"
+)
+
+// شرط انتخاب هندلر؛ فیلتر nil همه پیام‌ها را می‌پذیرد
+type Filter func(*Message) bool
+
+type HandlerID int
+
+type messageRoute struct {
+	id      HandlerID
+	group   int
+	filter  Filter
+	handler func(*Robot, *Message)
+}
+
+// ثبت هندلر در گروه ۰
+func (r *Robot) Handle(filter Filter, handler func(*Robot, *Message)) HandlerID {
+	return r.HandleGroup(0, filter, handler)
+}
+
+// گروه‌ها به ترتیب صعودی اجرا می‌شوند و در هر گروه فقط اولین هندلر منطبق اجرا می‌شود؛
+// با m.StopPropagation گروه‌های بعدی اجرا نمی‌شوند
+func (r *Robot) HandleGroup(group int, filter Filter, handler func(*Robot, *Message)) HandlerID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastHandlerID++
+	r.routes = append(r.routes, messageRoute{
+		id:      r.lastHandlerID,
+		group:   group,
+		filter:  filter,
+		handler: handler,
+	})
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		return r.routes[i].group < r.routes[j].group
+	})
+	return r.lastHandlerID
+}
+
+func (r *Robot) RemoveHandler(id HandlerID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, route := range r.routes {
+		if route.id == id {
+			r.routes = append(r.routes[:i], r.routes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Message) StopPropagation() {
+	m.stopped = true
+}
+
+// اجرای هندلرهای Handle به ترتیب گروه؛ اگر هیچ‌کدام منطبق نباشد OnMessage اجرا می‌شود
+func (r *Robot) dispatchMessage(m *Message) {
+	r.mu.Lock()
+	routes := make([]messageRoute, len(r.routes))
+	copy(routes, r.routes)
+	fallback := r.MessageHandler
+	r.mu.Unlock()
+
+	matched := false
+	for i := 0; i < len(routes) && !m.stopped; {
+		group := routes[i].group
+		for ; i < len(routes) && routes[i].group == group; i++ {
+			route := routes[i]
+			if route.filter == nil || route.filter(m) {
+				matched = true
+				route.handler(r, m)
+				break
+			}
+		}
+		for i < len(routes) && routes[i].group == group {
+			i++
+		}
+	}
+
+	if !matched && fallback != nil {
+		fallback(r, m)
+	}
+}
+
+func And(filters ...Filter) Filter {
+	return func(m *Message) bool {
+		for _, filter := range filters {
+			if filter != nil && !filter(m) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func Or(filters ...Filter) Filter {
+	return func(m *Message) bool {
+		for _, filter := range filters {
+			if filter == nil || filter(m) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func Not(filter Filter) Filter {
+	return func(m *Message) bool {
+		return filter != nil && !filter(m)
+	}
+}
+
+func TextMatches(re *regexp.Regexp) Filter {
+	return func(m *Message) bool {
+		return re.MatchString(m.Text)
+	}
+}
+
+// مثل TextMatches؛ الگوی نامعتبر باعث panic می‌شود
+func TextRegex(pattern string) Filter {
+	return TextMatches(regexp.MustCompile(pattern))
+}
+
+func TextEquals(texts ...string) Filter {
+	return func(m *Message) bool {
+		for _, text := range texts {
+			if strings.TrimSpace(m.Text) == text {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// نوع چت از پیشوند شناسه آن (g0 گروه، c0 کانال، بقیه کاربر)
+func (m *Message) ChatType() string {
+	switch {
+	case strings.HasPrefix(m.ChatID, "g0"):
+		return ChatTypeGroup
+	case strings.HasPrefix(m.ChatID, "c0"):
+		return ChatTypeChannel
+	}
+	return ChatTypeUser
+}
+
+func ChatType(types ...string) Filter {
+	return func(m *Message) bool {
+		chatType := m.ChatType()
+		for _, t := range types {
+			if t == chatType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func FromSenders(senderIDs ...string) Filter {
+	senders := make(map[string]bool, len(senderIDs))
+	for _, id := range senderIDs {
+		senders[id] = true
+	}
+	return func(m *Message) bool {
+		return senders[m.SenderID]
+	}
+}
+
+func HasFile() Filter {
+	return func(m *Message) bool {
+		return m.File != nil
+	}
+}
+
+func (m *Message) ReplyToMessageID() string {
+	id, _ := m.RawData["reply_to_message_id"].(string)
+	return id
+}
+
+func IsReply() Filter {
+	return func(m *Message) bool {
+		return m.ReplyToMessageID() != ""
+	}
+}
+
+func IsForwarded() Filter {
+	return func(m *Message) bool {
+		forwarded, ok := m.RawData["forwarded_from"].(map[string]interface{})
+		return ok && len(forwarded) > 0
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ترجمه پیام‌ها با کاتالوگ‌های JSON، YAML یا gettext (.po) و قواعد جمع
+type I18n struct {
+	mu            sync.RWMutex
+	DefaultLocale string
+	catalogs      map[string]map[string]catalogEntry
+}
+
+type catalogEntry struct {
+	text  string
+	forms map[string]string
+}
+
+type PluralRule func(n int) string
+
+var pluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// قواعد جمع CLDR برای زبان‌های پرکاربرد؛ با RegisterPluralRule قابل افزودن است
+var pluralRules = map[string]PluralRule{
+	"fa": func(n int) string {
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	"en": func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	"ar": func(n int) string {
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+		return "other"
+	},
+}
+
+// ترتیب msgstr[n] در فایل‌های gettext برای هر زبان
+var gettextPluralForms = map[string][]string{
+	"ar": {"zero", "one", "two", "few", "many", "other"},
+}
+
+var pluralMu sync.RWMutex
+
+func RegisterPluralRule(language string, rule PluralRule) {
+	pluralMu.Lock()
+	defer pluralMu.Unlock()
+
+	pluralRules[language] = rule
+}
+
+// زبان پایه لوکیل: ar_EG و ar-EG هر دو ar هستند
+func baseLanguage(locale string) string {
+	return strings.ToLower(strings.SplitN(strings.SplitN(locale, "-", 2)[0], "_", 2)[0])
+}
+
+func pluralCategory(locale string, n int) string {
+	language := baseLanguage(locale)
+
+	pluralMu.RLock()
+	rule, ok := pluralRules[language]
+	pluralMu.RUnlock()
+	if !ok {
+		rule = pluralRules["en"]
+	}
+	return rule(n)
+}
+
+func NewI18n(defaultLocale string) *I18n {
+	return &I18n{
+		DefaultLocale: defaultLocale,
+		catalogs:      make(map[string]map[string]catalogEntry),
+	}
+}
+
+func WithI18n(i18n *I18n) func(*Robot) {
+	return func(r *Robot) {
+		r.I18n = i18n
+	}
+}
+
+func (i *I18n) catalog(locale string) map[string]catalogEntry {
+	if i.catalogs[locale] == nil {
+		i.catalogs[locale] = make(map[string]catalogEntry)
+	}
+	return i.catalogs[locale]
+}
+
+func (i *I18n) AddMessages(locale string, messages map[string]string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	catalog := i.catalog(locale)
+	for key, text := range messages {
+		catalog[key] = catalogEntry{text: text}
+	}
+}
+
+func (i *I18n) AddPlural(locale, key string, forms map[string]string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.catalog(locale)[key] = catalogEntry{text: forms["other"], forms: forms}
+}
+
+func isPluralForms(values map[string]interface{}) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for key := range values {
+		found := false
+		for _, category := range pluralCategories {
+			if key == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// افزودن کاتالوگ درختی؛ کلیدهای تو در تو با نقطه به هم وصل می‌شوند
+func (i *I18n) addTree(locale, prefix string, tree map[string]interface{}) error {
+	for key, value := range tree {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			i.AddMessages(locale, map[string]string{fullKey: v})
+		case map[string]interface{}:
+			if isPluralForms(v) {
+				forms := make(map[string]string, len(v))
+				for category, text := range v {
+					forms[category], _ = text.(string)
+				}
+				i.AddPlural(locale, fullKey, forms)
+				continue
+			}
+			if err := i.addTree(locale, fullKey, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid value for key %q", fullKey)
+		}
+	}
+	return nil
+}
+
+func (i *I18n) LoadJSON(locale string, data []byte) error {
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	return i.addTree(locale, "", tree)
+}
+
+func (i *I18n) LoadYAML(locale string, data []byte) error {
+	parsed, err := parseYAML(string(data))
+	if err != nil {
+		return err
+	}
+	tree, ok := parsed.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("catalog must be a mapping")
+	}
+	return i.addTree(locale, "", tree)
+}
+
+// بارگذاری فایل gettext؛ msgid به عنوان کلید استفاده می‌شود
+func (i *I18n) LoadPO(locale string, data []byte) error {
+	forms := gettextPluralForms[baseLanguage(locale)]
+	if forms == nil {
+		forms = []string{"one", "other"}
+	}
+
+	var msgid, current string
+	msgstr := map[int]string{}
+	plural := false
+	index := -1
+
+	flush := func() {
+		if msgid != "" && len(msgstr) > 0 {
+			if plural {
+				entry := map[string]string{}
+				for n, text := range msgstr {
+					if n < len(forms) && text != "" {
+						entry[forms[n]] = text
+					}
+				}
+				i.AddPlural(locale, msgid, entry)
+			} else if msgstr[0] != "" {
+				i.AddMessages(locale, map[string]string{msgid: msgstr[0]})
+			}
+		}
+		msgid, current, plural, index = "", "", false, -1
+		msgstr = map[int]string{}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, `"`) {
+			value, err := strconv.Unquote(line)
+			if err != nil {
+				return fmt.Errorf("invalid po line: %s", line)
+			}
+			if current == "msgid" {
+				msgid += value
+			} else if current == "msgstr" {
+				msgstr[index] += value
+			}
+			continue
+		}
+
+		keyword, rest := line, ""
+		if sp := strings.IndexByte(line, ' '); sp >= 0 {
+			keyword, rest = line[:sp], strings.TrimSpace(line[sp+1:])
+		}
+		value, err := strconv.Unquote(rest)
+		if err != nil {
+			return fmt.Errorf("invalid po line: %s", line)
+		}
+
+		switch {
+		case keyword == "msgid":
+			flush()
+			current, msgid = "msgid", value
+		case keyword == "msgid_plural":
+			plural, current = true, ""
+		case keyword == "msgstr":
+			current, index = "msgstr", 0
+			msgstr[0] = value
+		case strings.HasPrefix(keyword, "msgstr["):
+			n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+			if err != nil {
+				return fmt.Errorf("invalid po line: %s", line)
+			}
+			current, index = "msgstr", n
+			msgstr[n] = value
+		default:
+			current = ""
+		}
+	}
+	flush()
+
+	return scanner.Err()
+}
+
+// بارگذاری همه کاتالوگ‌ها از fs.FS؛ زبان از نام فایل گرفته می‌شود (fa.json، en.yaml، fa.po)
+func (i *I18n) LoadFS(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		ext := path.Ext(file)
+		locale := strings.TrimSuffix(path.Base(file), ext)
+		switch ext {
+		case ".json":
+			err = i.LoadJSON(locale, data)
+		case ".yaml", ".yml":
+			err = i.LoadYAML(locale, data)
+		case ".po":
+			err = i.LoadPO(locale, data)
+		default:
+			err = fmt.Errorf("unsupported catalog format %q", ext)
+		}
+		if err != nil {
+			return fmt.Errorf("catalog %s: %v", file, err)
+		}
+	}
+	return nil
+}
+
+func (i *I18n) lookup(locale, key string) (catalogEntry, string, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	candidates := []string{locale}
+	if base := strings.SplitN(strings.SplitN(locale, "-", 2)[0], "_", 2)[0]; base != locale {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, i.DefaultLocale)
+
+	for _, candidate := range candidates {
+		if entry, ok := i.catalogs[candidate][key]; ok {
+			return entry, candidate, true
+		}
+	}
+	return catalogEntry{}, "", false
+}
+
+// ترجمه کلید؛ اگر اولین آرگومان map باشد {name} ها جایگزین می‌شوند و مقدار count برای جمع استفاده می‌شود،
+// در غیر این صورت آرگومان‌ها با fmt.Sprintf اعمال می‌شوند و اولین عدد صحیح تعیین‌کننده جمع است
+func (i *I18n) Translate(locale, key string, args ...interface{}) string {
+	entry, foundLocale, ok := i.lookup(locale, key)
+	if !ok {
+		return key
+	}
+
+	var named map[string]interface{}
+	if len(args) == 1 {
+		named, _ = args[0].(map[string]interface{})
+	}
+
+	text := entry.text
+	if entry.forms != nil {
+		if count, ok := pluralCount(named, args); ok {
+			if form, ok := entry.forms[pluralCategory(foundLocale, count)]; ok {
+				text = form
+			}
+		}
+	}
+
+	if named != nil {
+		for name, value := range named {
+			text = strings.ReplaceAll(text, "{"+name+"}", fmt.Sprint(value))
+		}
+		return text
+	}
+	if len(args) > 0 && strings.Contains(text, "%") {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+func pluralCount(named map[string]interface{}, args []interface{}) (int, bool) {
+	if named != nil {
+		return toInt(named["count"])
+	}
+	for _, arg := range args {
+		if n, ok := toInt(arg); ok {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+func localeKey(senderID string) string {
+	return "locale:" + senderID
+}
+
+// زبان کاربر از Storage خوانده می‌شود؛ در صورت نبود، زبان پیش‌فرض برگردانده می‌شود
+func (m *Message) Locale() string {
+	if locale, ok, err := m.Bot.storage().Get(localeKey(m.SenderID)); err == nil && ok && locale != "" {
+		return locale
+	}
+	if m.Bot.I18n != nil {
+		return m.Bot.I18n.DefaultLocale
+	}
+	return ""
+}
+
+func (m *Message) SetLocale(locale string) error {
+	return m.Bot.storage().Set(localeKey(m.SenderID), locale)
+}
+
+func (m *Message) T(key string, args ...interface{}) string {
+	if m.Bot.I18n == nil {
+		return key
+	}
+	return m.Bot.I18n.Translate(m.Locale(), key, args...)
+}
+
+// دکمه با متن ترجمه‌شده؛ شناسه دکمه برای همه زبان‌ها ثابت است و با همان OnCallback مسیریابی می‌شود
+func (m *Message) TButton(buttonID, key string, args ...interface{}) map[string]interface{} {
+	return CreateInlineButton(m.T(key, args...), buttonID)
+}
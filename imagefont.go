@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// فونت بیت‌مپ ۵×۷ برای واترمارک متنی (فقط حروف و ارقام لاتین)
+var glyphs5x7 = map[rune][7]string{
+	'0': {"01110", "10001", "10011", "10101", "11001", "10001", "01110"},
+	'1': {"00100", "01100", "00100", "00100", "00100", "00100", "01110"},
+	'2': {"01110", "10001", "00001", "00010", "00100", "01000", "11111"},
+	'3': {"11110", "00001", "00001", "01110", "00001", "00001", "11110"},
+	'4': {"00010", "00110", "01010", "10010", "11111", "00010", "00010"},
+	'5': {"11111", "10000", "11110", "00001", "00001", "10001", "01110"},
+	'6': {"00110", "01000", "10000", "11110", "10001", "10001", "01110"},
+	'7': {"11111", "00001", "00010", "00100", "01000", "01000", "01000"},
+	'8': {"01110", "10001", "10001", "01110", "10001", "10001", "01110"},
+	'9': {"01110", "10001", "10001", "01111", "00001", "00010", "01100"},
+	'A': {"01110", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'B': {"11110", "10001", "10001", "11110", "10001", "10001", "11110"},
+	'C': {"01110", "10001", "10000", "10000", "10000", "10001", "01110"},
+	'D': {"11100", "10010", "10001", "10001", "10001", "10010", "11100"},
+	'E': {"11111", "10000", "10000", "11110", "10000", "10000", "11111"},
+	'F': {"11111", "10000", "10000", "11110", "10000", "10000", "10000"},
+	'G': {"01110", "10001", "10000", "10111", "10001", "10001", "01111"},
+	'H': {"10001", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'I': {"01110", "00100", "00100", "00100", "00100", "00100", "01110"},
+	'J': {"00111", "00010", "00010", "00010", "00010", "10010", "01100"},
+	'K': {"10001", "10010", "10100", "11000", "10100", "10010", "10001"},
+	'L': {"10000", "10000", "10000", "10000", "10000", "10000", "11111"},
+	'M': {"10001", "11011", "10101", "10101", "10001", "10001", "10001"},
+	'N': {"10001", "10001", "11001", "10101", "10011", "10001", "10001"},
+	'O': {"01110", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'P': {"11110", "10001", "10001", "11110", "10000", "10000", "10000"},
+	'Q': {"01110", "10001", "10001", "10001", "10101", "10010", "01101"},
+	'R': {"11110", "10001", "10001", "11110", "10100", "10010", "10001"},
+	'S': {"01111", "10000", "10000", "01110", "00001", "00001", "11110"},
+	'T': {"11111", "00100", "00100", "00100", "00100", "00100", "00100"},
+	'U': {"10001", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'V': {"10001", "10001", "10001", "10001", "10001", "01010", "00100"},
+	'W': {"10001", "10001", "10001", "10101", "10101", "10101", "01010"},
+	'X': {"10001", "10001", "01010", "00100", "01010", "10001", "10001"},
+	'Y': {"10001", "10001", "10001", "01010", "00100", "00100", "00100"},
+	'Z': {"11111", "00001", "00010", "00100", "01000", "10000", "11111"},
+	' ': {"00000", "00000", "00000", "00000", "00000", "00000", "00000"},
+	'.': {"00000", "00000", "00000", "00000", "00000", "01100", "01100"},
+	',': {"00000", "00000", "00000", "00000", "01100", "00100", "01000"},
+	'-': {"00000", "00000", "00000", "11111", "00000", "00000", "00000"},
+	'_': {"00000", "00000", "00000", "00000", "00000", "00000", "11111"},
+	':': {"00000", "01100", "01100", "00000", "01100", "01100", "00000"},
+	'/': {"00000", "00001", "00010", "00100", "01000", "10000", "00000"},
+	'@': {"01110", "10001", "00001", "01101", "10101", "10101", "01110"},
+	'!': {"00100", "00100", "00100", "00100", "00100", "00000", "00100"},
+	'?': {"01110", "10001", "00001", "00010", "00100", "00000", "00100"},
+	'#': {"01010", "01010", "11111", "01010", "11111", "01010", "01010"},
+	'+': {"00000", "00100", "00100", "11111", "00100", "00100", "00000"},
+	'&': {"01100", "10010", "10100", "01000", "10101", "10010", "01101"},
+}
+
+// رسم متن با فونت بیت‌مپ؛ اندازه حروف متناسب با عرض تصویر انتخاب می‌شود.
+// فونت فقط لاتین است؛ برای متن فارسی باید تصویر واترمارک (Watermark) داده شود
+func renderText(text string, imageWidth int) (image.Image, error) {
+	text = strings.ToUpper(text)
+	runes := []rune(text)
+	for _, ch := range runes {
+		if _, ok := glyphs5x7[ch]; !ok {
+			return nil, fmt.Errorf("watermark text: character %q is not supported by the built-in Latin font, use Watermark with an image instead", ch)
+		}
+	}
+	if len(runes) == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 1, 1)), nil
+	}
+
+	// هر حرف ۵ پیکسل به اضافه ۱ پیکسل فاصله؛ متن حدوداً یک‌سوم عرض تصویر
+	scale := imageWidth / 3 / (len(runes) * 6)
+	if scale < 1 {
+		scale = 1
+	}
+
+	width := (len(runes)*6 + 1) * scale
+	height := 9 * scale
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	shadow := color.RGBA{A: 255}
+	fill := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	// اول سایه و سپس خود متن رسم می‌شود تا سایه روی حروف مجاور نیفتد
+	offset := scale/2 + 1
+	drawRunes(img, runes, scale, offset, shadow)
+	drawRunes(img, runes, scale, 0, fill)
+
+	return img, nil
+}
+
+func drawRunes(img *image.RGBA, runes []rune, scale, offset int, c color.RGBA) {
+	for i, ch := range runes {
+		glyph := glyphs5x7[ch]
+		originX := (i*6+1)*scale + offset
+		originY := scale + offset
+
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit == '1' {
+					fillRect(img, originX+col*scale, originY+row*scale, scale, c)
+				}
+			}
+		}
+	}
+}
+
+func fillRect(img *image.RGBA, x, y, size int, c color.RGBA) {
+	for dy := 0; dy < size; dy++ {
+		for dx := 0; dx < size; dx++ {
+			if image.Pt(x+dx, y+dy).In(img.Bounds()) {
+				img.SetRGBA(x+dx, y+dy, c)
+			}
+		}
+	}
+}
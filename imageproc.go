@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type WatermarkPosition int
+
+const (
+	WatermarkBottomRight WatermarkPosition = iota
+	WatermarkBottomLeft
+	WatermarkTopRight
+	WatermarkTopLeft
+	WatermarkCenter
+)
+
+// تنظیمات پیش‌پردازش تصویر قبل از آپلود
+type ImageOptions struct {
+	MaxWidth          int
+	MaxHeight         int
+	Quality           int
+	Format            string
+	Watermark         image.Image
+	WatermarkText     string
+	WatermarkPosition WatermarkPosition
+	WatermarkOpacity  float64
+}
+
+func WithImageProcessing(options ImageOptions) func(*Robot) {
+	return func(r *Robot) {
+		r.ImageOptions = &options
+	}
+}
+
+// اجرای پیش‌پردازش روی تصویر و برگرداندن فایل جدید؛ GIF متحرک بدون تغییر برگردانده می‌شود
+func ProcessImage(input InputFile, options ImageOptions) (InputFile, error) {
+	data, err := io.ReadAll(input.Reader)
+	if err != nil {
+		return input, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return input, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	if format == "gif" {
+		if all, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(all.Image) > 1 {
+			return FileFromBytes(input.Name, data), nil
+		}
+	}
+
+	// تصویر دوباره encode می‌شود و EXIF از بین می‌رود، پس چرخش همیشه اعمال می‌شود
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	img = resizeToFit(img, options.MaxWidth, options.MaxHeight)
+
+	if options.Watermark != nil || options.WatermarkText != "" {
+		if img, err = applyWatermark(img, options); err != nil {
+			return input, err
+		}
+	}
+
+	outFormat := strings.ToLower(options.Format)
+	if outFormat == "" {
+		outFormat = format
+		if outFormat == "gif" {
+			outFormat = "png"
+		}
+		if options.Quality > 0 {
+			outFormat = "jpeg"
+		}
+	}
+
+	encoded, err := encodeImage(img, outFormat, options.Quality)
+	if err != nil {
+		return input, err
+	}
+
+	name := strings.TrimSuffix(input.Name, filepath.Ext(input.Name))
+	if outFormat == "jpeg" {
+		name += ".jpg"
+	} else {
+		name += "." + outFormat
+	}
+
+	return FileFromBytes(name, encoded), nil
+}
+
+func encodeImage(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case "jpeg", "jpg":
+		if quality <= 0 {
+			quality = jpeg.DefaultQuality
+		}
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+	case "png":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported image format: %s", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ساخت تصویر بندانگشتی JPEG با حداکثر ابعاد مشخص
+func MakeThumbnail(file interface{}, maxDimension int) ([]byte, error) {
+	input, closeFn, err := openInputFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+
+	data, err := io.ReadAll(input.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	return encodeImage(resizeToFit(img, maxDimension, maxDimension), "jpeg", 80)
+}
+
+// تغییر اندازه با حفظ نسبت ابعاد و درون‌یابی دوخطی
+func resizeToFit(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		if s := float64(maxHeight) / float64(height); s < scale {
+			scale = s
+		}
+	}
+	if scale >= 1 {
+		return img
+	}
+
+	newWidth := int(float64(width)*scale + 0.5)
+	newHeight := int(float64(height)*scale + 0.5)
+	if newWidth < 1 {
+		newWidth = 1
+	}
+	if newHeight < 1 {
+		newHeight = 1
+	}
+
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+
+	xRatio := float64(width) / float64(newWidth)
+	yRatio := float64(height) / float64(newHeight)
+
+	for y := 0; y < newHeight; y++ {
+		sy := (float64(y)+0.5)*yRatio - 0.5
+		y0 := clampInt(int(sy), 0, height-1)
+		y1 := clampInt(y0+1, 0, height-1)
+		fy := sy - float64(y0)
+		if fy < 0 {
+			fy = 0
+		}
+
+		for x := 0; x < newWidth; x++ {
+			sx := (float64(x)+0.5)*xRatio - 0.5
+			x0 := clampInt(int(sx), 0, width-1)
+			x1 := clampInt(x0+1, 0, width-1)
+			fx := sx - float64(x0)
+			if fx < 0 {
+				fx = 0
+			}
+
+			i00 := src.PixOffset(x0, y0)
+			i10 := src.PixOffset(x1, y0)
+			i01 := src.PixOffset(x0, y1)
+			i11 := src.PixOffset(x1, y1)
+			o := dst.PixOffset(x, y)
+
+			for c := 0; c < 4; c++ {
+				top := float64(src.Pix[i00+c])*(1-fx) + float64(src.Pix[i10+c])*fx
+				bottom := float64(src.Pix[i01+c])*(1-fx) + float64(src.Pix[i11+c])*fx
+				dst.Pix[o+c] = uint8(top*(1-fy) + bottom*fy + 0.5)
+			}
+		}
+	}
+
+	return dst
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// خواندن تگ Orientation از بخش EXIF فایل JPEG؛ در صورت نبود مقدار ۱ برگردانده می‌شود
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// اعمال چرخش و قرینه‌سازی بر اساس مقدار Orientation در EXIF
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	src := toRGBA(img)
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+
+	return dst
+}
+
+func applyWatermark(img image.Image, options ImageOptions) (image.Image, error) {
+	mark := options.Watermark
+	if mark == nil {
+		text, err := renderText(options.WatermarkText, img.Bounds().Dx())
+		if err != nil {
+			return nil, err
+		}
+		mark = text
+	}
+
+	opacity := options.WatermarkOpacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = 0.5
+	}
+
+	dst := toRGBA(img)
+	bounds := dst.Bounds()
+	markBounds := mark.Bounds()
+	margin := bounds.Dx() / 50
+
+	var x, y int
+	switch options.WatermarkPosition {
+	case WatermarkTopLeft:
+		x, y = margin, margin
+	case WatermarkTopRight:
+		x, y = bounds.Dx()-markBounds.Dx()-margin, margin
+	case WatermarkBottomLeft:
+		x, y = margin, bounds.Dy()-markBounds.Dy()-margin
+	case WatermarkCenter:
+		x, y = (bounds.Dx()-markBounds.Dx())/2, (bounds.Dy()-markBounds.Dy())/2
+	default:
+		x, y = bounds.Dx()-markBounds.Dx()-margin, bounds.Dy()-markBounds.Dy()-margin
+	}
+
+	mask := image.NewUniform(color.Alpha{A: uint8(opacity * 255)})
+	target := image.Rect(x, y, x+markBounds.Dx(), y+markBounds.Dy())
+	draw.DrawMask(dst, target, mark, markBounds.Min, mask, image.Point{}, draw.Over)
+
+	return dst, nil
+}
+
+func (r *Robot) preprocessImage(file interface{}, options ImageOptions) (InputFile, error) {
+	input, closeFn, err := openInputFile(file)
+	if err != nil {
+		return InputFile{}, err
+	}
+	defer closeFn()
+
+	return ProcessImage(input, options)
+}
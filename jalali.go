@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// تاریخ هجری شمسی
+type JalaliDate struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+var JalaliMonthNames = [12]string{
+	"فروردین", "اردیبهشت", "خرداد", "تیر", "مرداد", "شهریور",
+	"مهر", "آبان", "آذر", "دی", "بهمن", "اسفند",
+}
+
+// نام روزهای هفته به ترتیب time.Weekday (یکشنبه تا شنبه)
+var PersianWeekdayNames = [7]string{
+	"یکشنبه", "دوشنبه", "سه‌شنبه", "چهارشنبه", "پنجشنبه", "جمعه", "شنبه",
+}
+
+// سال‌های شکست چرخه ۳۳ ساله (الگوریتم بورکوفسکی)
+var jalaliBreaks = []int{
+	-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210,
+	1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178,
+}
+
+func jalaliCal(jy int) (leap, gy, march int) {
+	gy = jy + 621
+	leapJ := -14
+	jp := jalaliBreaks[0]
+	jump := 0
+
+	for i := 1; i < len(jalaliBreaks); i++ {
+		jm := jalaliBreaks[i]
+		jump = jm - jp
+		if jy < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+
+	n := jy - jp
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march = 20 + leapJ - leapG
+
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap = ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+	return leap, gy, march
+}
+
+func gregorianToJDN(gy, gm, gd int) int {
+	d := (gy+(gm-8)/6+100100)*1461/4 + (153*((gm+9)%12)+2)/5 + gd - 34840408
+	return d - (gy+100100+(gm-8)/6)/100*3/4 + 752
+}
+
+func jdnToGregorian(jdn int) (int, int, int) {
+	j := 4*jdn + 139361631
+	j += (4*jdn+183187720)/146097*3/4*4 - 3908
+	i := j%1461/4*5 + 308
+	gd := i%153/5 + 1
+	gm := i/153%12 + 1
+	gy := j/1461 - 100100 + (8-gm)/6
+	return gy, gm, gd
+}
+
+func jalaliToJDN(jy, jm, jd int) int {
+	_, gy, march := jalaliCal(jy)
+	return gregorianToJDN(gy, 3, march) + (jm-1)*31 - jm/7*(jm-7) + jd - 1
+}
+
+func jdnToJalali(jdn int) JalaliDate {
+	gy, _, _ := jdnToGregorian(jdn)
+	jy := gy - 621
+	leap, _, march := jalaliCal(jy)
+	k := jdn - gregorianToJDN(gy, 3, march)
+
+	if k >= 0 {
+		if k <= 185 {
+			return JalaliDate{Year: jy, Month: 1 + k/31, Day: k%31 + 1}
+		}
+		k -= 186
+	} else {
+		jy--
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	}
+	return JalaliDate{Year: jy, Month: 7 + k/30, Day: k%30 + 1}
+}
+
+func ToJalali(t time.Time) JalaliDate {
+	return jdnToJalali(gregorianToJDN(t.Year(), int(t.Month()), t.Day()))
+}
+
+// تبدیل تاریخ شمسی به time.Time در ساعت ۰۰:۰۰ منطقه زمانی داده‌شده
+func (d JalaliDate) Time(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	gy, gm, gd := jdnToGregorian(jalaliToJDN(d.Year, d.Month, d.Day))
+	return time.Date(gy, time.Month(gm), gd, 0, 0, 0, 0, loc)
+}
+
+func (d JalaliDate) Weekday() time.Weekday {
+	return d.Time(time.UTC).Weekday()
+}
+
+func (d JalaliDate) Valid() bool {
+	return d.Month >= 1 && d.Month <= 12 && d.Day >= 1 && d.Day <= JalaliMonthLength(d.Year, d.Month)
+}
+
+// افزودن ماه با حفظ روز در محدوده ماه جدید
+func (d JalaliDate) AddMonths(months int) JalaliDate {
+	total := d.Year*12 + d.Month - 1 + months
+	year, month := total/12, total%12+1
+	if total < 0 && total%12 != 0 {
+		year, month = total/12-1, total%12+13
+	}
+	day := d.Day
+	if length := JalaliMonthLength(year, month); day > length {
+		day = length
+	}
+	return JalaliDate{Year: year, Month: month, Day: day}
+}
+
+func (d JalaliDate) String() string {
+	return fmt.Sprintf("%04d/%02d/%02d", d.Year, d.Month, d.Day)
+}
+
+func IsJalaliLeap(year int) bool {
+	leap, _, _ := jalaliCal(year)
+	return leap == 0
+}
+
+func JalaliMonthLength(year, month int) int {
+	switch {
+	case month <= 6:
+		return 31
+	case month <= 11:
+		return 30
+	case IsJalaliLeap(year):
+		return 30
+	}
+	return 29
+}
+
+// قالب‌بندی تاریخ شمسی؛ توکن‌ها: YYYY، YY، MMMM (نام ماه)، MM، M، DD، D، dddd (نام روز)، HH، mm، ss
+func FormatJalali(t time.Time, layout string) string {
+	d := ToJalali(t)
+	tokens := []struct {
+		token string
+		value func() string
+	}{
+		{"YYYY", func() string { return fmt.Sprintf("%04d", d.Year) }},
+		{"YY", func() string { return fmt.Sprintf("%02d", d.Year%100) }},
+		{"MMMM", func() string { return JalaliMonthNames[d.Month-1] }},
+		{"MM", func() string { return fmt.Sprintf("%02d", d.Month) }},
+		{"M", func() string { return strconv.Itoa(d.Month) }},
+		{"DD", func() string { return fmt.Sprintf("%02d", d.Day) }},
+		{"D", func() string { return strconv.Itoa(d.Day) }},
+		{"dddd", func() string { return PersianWeekdayNames[t.Weekday()] }},
+		{"HH", func() string { return fmt.Sprintf("%02d", t.Hour()) }},
+		{"mm", func() string { return fmt.Sprintf("%02d", t.Minute()) }},
+		{"ss", func() string { return fmt.Sprintf("%02d", t.Second()) }},
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(layout); {
+		matched := false
+		for _, tk := range tokens {
+			if strings.HasPrefix(layout[i:], tk.token) {
+				b.WriteString(tk.value())
+				i += len(tk.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(layout[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// مثل FormatJalali با ارقام فارسی
+func FormatJalaliPersian(t time.Time, layout string) string {
+	return ToPersianDigits(FormatJalali(t, layout))
+}
+
+// پارس تاریخ شمسی به شکل 1403/01/15 یا ۱۴۰۳-۰۱-۱۵
+func ParseJalali(text string, loc *time.Location) (time.Time, error) {
+	text = strings.TrimSpace(ToLatinDigits(text))
+	fields := strings.FieldsFunc(text, func(ch rune) bool { return ch == '/' || ch == '-' })
+	if len(fields) != 3 {
+		return time.Time{}, fmt.Errorf("invalid jalali date %q", text)
+	}
+
+	var parts [3]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid jalali date %q", text)
+		}
+		parts[i] = n
+	}
+
+	d := JalaliDate{Year: parts[0], Month: parts[1], Day: parts[2]}
+	if !d.Valid() {
+		return time.Time{}, fmt.Errorf("invalid jalali date %q", text)
+	}
+	return d.Time(loc), nil
+}
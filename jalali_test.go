@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJalaliConversion(t *testing.T) {
+	tests := []struct {
+		gregorian time.Time
+		jalali    JalaliDate
+	}{
+		{time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), JalaliDate{Year: 1403, Month: 1, Day: 1}},
+		{time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC), JalaliDate{Year: 1403, Month: 12, Day: 30}},
+		{time.Date(2025, 3, 21, 0, 0, 0, 0, time.UTC), JalaliDate{Year: 1404, Month: 1, Day: 1}},
+		{time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC), JalaliDate{Year: 1401, Month: 12, Day: 29}},
+		{time.Date(1979, 2, 11, 0, 0, 0, 0, time.UTC), JalaliDate{Year: 1357, Month: 11, Day: 22}},
+		{time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), JalaliDate{Year: 1378, Month: 10, Day: 11}},
+	}
+
+	for _, tt := range tests {
+		if got := ToJalali(tt.gregorian); got != tt.jalali {
+			t.Errorf("ToJalali(%s) = %s, want %s", tt.gregorian.Format("2006-01-02"), got, tt.jalali)
+		}
+		if got := tt.jalali.Time(time.UTC); !got.Equal(tt.gregorian) {
+			t.Errorf("%s.Time() = %s, want %s", tt.jalali, got.Format("2006-01-02"), tt.gregorian.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestJalaliRoundTrip(t *testing.T) {
+	start := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	for day := start; day.Year() < 2060; day = day.AddDate(0, 0, 1) {
+		d := ToJalali(day)
+		if !d.Valid() {
+			t.Fatalf("ToJalali(%s) = %s is not valid", day.Format("2006-01-02"), d)
+		}
+		if back := d.Time(time.UTC); !back.Equal(day) {
+			t.Fatalf("round trip %s -> %s -> %s", day.Format("2006-01-02"), d, back.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestJalaliLeapYears(t *testing.T) {
+	for year, leap := range map[int]bool{1399: true, 1400: false, 1403: true, 1404: false, 1408: true} {
+		if got := IsJalaliLeap(year); got != leap {
+			t.Errorf("IsJalaliLeap(%d) = %v, want %v", year, got, leap)
+		}
+	}
+}
+
+func TestJalaliAddMonths(t *testing.T) {
+	tests := []struct {
+		from   JalaliDate
+		months int
+		want   JalaliDate
+	}{
+		{JalaliDate{Year: 1403, Month: 6, Day: 31}, 1, JalaliDate{Year: 1403, Month: 7, Day: 30}},
+		{JalaliDate{Year: 1403, Month: 12, Day: 15}, 1, JalaliDate{Year: 1404, Month: 1, Day: 15}},
+		{JalaliDate{Year: 1403, Month: 1, Day: 1}, -1, JalaliDate{Year: 1402, Month: 12, Day: 1}},
+		{JalaliDate{Year: 1403, Month: 11, Day: 30}, 1, JalaliDate{Year: 1403, Month: 12, Day: 30}},
+		{JalaliDate{Year: 1404, Month: 11, Day: 30}, 1, JalaliDate{Year: 1404, Month: 12, Day: 29}},
+	}
+	for _, tt := range tests {
+		if got := tt.from.AddMonths(tt.months); got != tt.want {
+			t.Errorf("%s.AddMonths(%d) = %s, want %s", tt.from, tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestParseJalali(t *testing.T) {
+	got, err := ParseJalali("۱۴۰۳/۰۱/۱۵", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseJalali = %s, want %s", got, want)
+	}
+
+	for _, input := range []string{"1403/13/01", "1404/12/30", "1403-01", "abc"} {
+		if _, err := ParseJalali(input, time.UTC); err == nil {
+			t.Errorf("ParseJalali(%q) expected error", input)
+		}
+	}
+}
+
+func TestFormatJalali(t *testing.T) {
+	when := time.Date(2024, 3, 20, 9, 5, 7, 0, time.UTC)
+	if got, want := FormatJalali(when, "YYYY/MM/DD HH:mm:ss"), "1403/01/01 09:05:07"; got != want {
+		t.Errorf("FormatJalali = %q, want %q", got, want)
+	}
+	if got, want := FormatJalaliPersian(when, "D MMMM YYYY"), "۱ فروردین ۱۴۰۳"; got != want {
+		t.Errorf("FormatJalaliPersian = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const defaultUploadConcurrency = 3
+
+type MediaItem struct {
+	File      interface{}
+	MediaType string
+	Caption   string
+}
+
+type MediaResult struct {
+	Index     int
+	MediaType string
+	FileID    string
+	Sent      *SentMessage
+	Err       error
+}
+
+func WithUploadConcurrency(concurrency int) func(*Robot) {
+	return func(r *Robot) {
+		r.UploadConcurrency = concurrency
+	}
+}
+
+// آیتم آماده ارسال؛ ورودی تا پایان ارسال باز می‌ماند تا اگر file_id کش‌شده رد شد دوباره آپلود شود
+type preparedMedia struct {
+	input     InputFile
+	mediaType string
+	cacheKey  string
+	cached    bool
+	closeFn   func() error
+}
+
+func (p *preparedMedia) Close() {
+	if p != nil && p.closeFn != nil {
+		p.closeFn()
+	}
+}
+
+// تشخیص نوع، پیش‌پردازش تصویر و آپلود (یا برداشتن file_id از کش) مثل SendFile و SendImage
+func (r *Robot) uploadMediaItem(ctx context.Context, item MediaItem) (*preparedMedia, string, error) {
+	input, closeFn, err := openInputFile(item.File)
+	if err != nil {
+		return nil, "", err
+	}
+	prepared := &preparedMedia{closeFn: closeFn}
+
+	mediaType := item.MediaType
+	if mediaType == "" {
+		var head []byte
+		input, head, err = sniffInput(input)
+		if err != nil {
+			return prepared, "", err
+		}
+		mediaType = DetectMediaType(input.Name, head)
+	}
+	prepared.mediaType = mediaType
+
+	if mediaType == "Image" && r.ImageOptions != nil {
+		if input, err = ProcessImage(input, *r.ImageOptions); err != nil {
+			return prepared, "", err
+		}
+	}
+	prepared.input = input
+
+	if err := validateMediaSize(mediaType, input.Size); err != nil {
+		return prepared, "", err
+	}
+
+	if prepared.cacheKey, err = r.fileCacheKeyFor(input, mediaType); err != nil {
+		return prepared, "", err
+	}
+	if fileID, ok := r.cachedFileID(prepared.cacheKey); ok {
+		prepared.cached = true
+		return prepared, fileID, nil
+	}
+
+	fileID, err := r.uploadAndCache(ctx, prepared.cacheKey, input, mediaType)
+	return prepared, fileID, err
+}
+
+// آپلود هم‌زمان همه آیتم‌ها با تعداد محدود کارگر؛ اگر cancelOnError داده شود اولین خطا بقیه را لغو می‌کند
+func (r *Robot) uploadMediaItems(ctx context.Context, items []MediaItem, cancelOnError context.CancelFunc) ([]MediaResult, []*preparedMedia) {
+	concurrency := r.UploadConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultUploadConcurrency
+	}
+
+	results := make([]MediaResult, len(items))
+	prepared := make([]*preparedMedia, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item MediaItem) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := MediaResult{Index: i}
+			if err := ctx.Err(); err != nil {
+				result.Err = err
+			} else {
+				prepared[i], result.FileID, result.Err = r.uploadMediaItem(ctx, item)
+				if prepared[i] != nil {
+					result.MediaType = prepared[i].mediaType
+				}
+			}
+			if result.Err != nil && cancelOnError != nil {
+				cancelOnError()
+			}
+			results[i] = result
+		}(i, item)
+	}
+
+	wg.Wait()
+	return results, prepared
+}
+
+// ارسال آیتم؛ file_id کش‌شده‌ای که سرور رد کند با آپلود دوباره جایگزین می‌شود
+func (r *Robot) sendMediaItem(ctx context.Context, chatID string, result *MediaResult, prepared *preparedMedia, options []SendOption) (*SentMessage, error) {
+	if prepared == nil || !prepared.cached {
+		return r.SendFileByID(chatID, result.FileID, options...)
+	}
+
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+	}
+	if err := r.applySendOptions(payload, options); err != nil {
+		return nil, err
+	}
+	if sent, accepted, err := r.sendCachedFileID(chatID, prepared.cacheKey, result.FileID, payload); accepted {
+		return sent, err
+	}
+
+	fileID, err := r.uploadAndCache(ctx, prepared.cacheKey, prepared.input, prepared.mediaType)
+	if err != nil {
+		return nil, err
+	}
+	result.FileID = fileID
+	return r.SendFileByID(chatID, fileID, options...)
+}
+
+func (r *Robot) sendMediaGroup(chatID string, items []MediaItem, caption string, atomic bool, options []SendOption) ([]MediaResult, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("media group is empty")
+	}
+	if _, err := newSendOptions(options); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var cancelOnError context.CancelFunc
+	if atomic {
+		cancelOnError = cancel
+	}
+	results, prepared := r.uploadMediaItems(ctx, items, cancelOnError)
+	defer func() {
+		for _, p := range prepared {
+			p.Close()
+		}
+	}()
+
+	failed := 0
+	var firstErr error
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		failed++
+		if firstErr == nil && !errors.Is(result.Err, context.Canceled) {
+			firstErr = fmt.Errorf("upload of item %d failed: %v", result.Index, result.Err)
+		}
+	}
+	if atomic && failed > 0 {
+		if firstErr == nil {
+			firstErr = context.Canceled
+		}
+		return results, fmt.Errorf("%v, nothing was sent", firstErr)
+	}
+
+	var sentIDs []string
+	captionSent := false
+
+	// ارسال به ترتیب آیتم‌ها؛ کپشن کلی روی اولین پیام ارسال‌شده قرار می‌گیرد
+	for i, item := range items {
+		if results[i].Err != nil {
+			continue
+		}
+
+		text := item.Caption
+		if !captionSent && caption != "" {
+			if text != "" {
+				text = caption + "\n\n" + text
+			} else {
+				text = caption
+			}
+			captionSent = true
+		}
+
+		itemOptions := options[:len(options):len(options)]
+		if text != "" {
+			itemOptions = append(itemOptions, WithCaption(text))
+		}
+
+		sent, err := r.sendMediaItem(ctx, chatID, &results[i], prepared[i], itemOptions)
+		results[i].Sent = sent
+		results[i].Err = err
+
+		if err != nil {
+			failed++
+			if atomic {
+				// پیام‌های ارسال‌شده قبلی حذف می‌شوند تا آلبوم ناقص باقی نماند
+				for _, messageID := range sentIDs {
+					r.DeleteMessage(chatID, messageID)
+				}
+				return results, fmt.Errorf("failed to send item %d: %v", i, err)
+			}
+			continue
+		}
+
+		if sent.MessageID != "" {
+			sentIDs = append(sentIDs, sent.MessageID)
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d items failed", failed, len(items))
+	}
+	return results, nil
+}
+
+// ارسال چند مدیا به ترتیب؛ در صورت خطا در هر آیتم هیچ پیامی باقی نمی‌ماند
+func (r *Robot) SendMediaGroup(chatID string, items []MediaItem, caption string, options ...SendOption) ([]MediaResult, error) {
+	return r.sendMediaGroup(chatID, items, caption, true, options)
+}
+
+// مثل SendMediaGroup، اما آیتم‌های موفق ارسال می‌شوند و نتیجه هر آیتم جداگانه گزارش می‌شود
+func (r *Robot) SendMediaGroupPartial(chatID string, items []MediaItem, caption string, options ...SendOption) ([]MediaResult, error) {
+	return r.sendMediaGroup(chatID, items, caption, false, options)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// حداکثر حجم هر نوع مدیا که SendAuto و SendMediaGroup قبل از آپلود بررسی می‌کنند؛
+// روبیکا محدودیت‌ها را مستند نکرده، پس به صورت پیش‌فرض خالی است و سرور تصمیم می‌گیرد
+var MediaSizeLimits = map[string]int64{}
+
+// تشخیص نوع مدیای روبیکا از روی محتوا و پسوند فایل
+func DetectMediaType(name string, head []byte) string {
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" || strings.HasPrefix(contentType, "text/plain") {
+		if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); byExt != "" {
+			contentType = byExt
+		}
+	}
+	contentType, _, _ = mime.ParseMediaType(contentType)
+
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case contentType == "image/gif":
+		return "Gif"
+	case strings.HasPrefix(contentType, "image/"):
+		return "Image"
+	case contentType == "audio/ogg" || contentType == "application/ogg" || ext == ".ogg" || ext == ".opus" || ext == ".oga":
+		return "Voice"
+	case strings.HasPrefix(contentType, "audio/"):
+		return "Music"
+	case strings.HasPrefix(contentType, "video/"):
+		return "Video"
+	}
+	return "File"
+}
+
+func validateMediaSize(mediaType string, size int64) error {
+	limit, ok := MediaSizeLimits[mediaType]
+	if !ok || size < 0 {
+		return nil
+	}
+	if size > limit {
+		return fmt.Errorf("%s size %s exceeds limit of %s", mediaType, formatBytes(size), formatBytes(limit))
+	}
+	return nil
+}
+
+// خواندن ابتدای فایل برای تشخیص نوع بدون از دست دادن داده
+func sniffInput(input InputFile) (InputFile, []byte, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(input.Reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return input, nil, err
+	}
+	head = head[:n]
+
+	if seeker, ok := input.Reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(int64(-n), io.SeekCurrent); err == nil {
+			return input, head, nil
+		}
+	}
+
+	input.Reader = io.MultiReader(bytes.NewReader(head), input.Reader)
+	return input, head, nil
+}
+
+// ارسال فایل با تشخیص خودکار نوع مدیا (Image، Gif، Voice، Music، Video یا File)؛
+// تصویرها مثل SendImage با WithImageProcessing پیش‌پردازش می‌شوند
+func (r *Robot) SendAuto(chatID string, file interface{}, options ...SendOption) (*SentMessage, error) {
+	input, closeFn, err := openInputFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+
+	input, head, err := sniffInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := DetectMediaType(input.Name, head)
+	if mediaType == "Image" && r.ImageOptions != nil {
+		if input, err = ProcessImage(input, *r.ImageOptions); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateMediaSize(mediaType, input.Size); err != nil {
+		return nil, err
+	}
+
+	return r.SendFile(chatID, input, mediaType, options...)
+}
+
+func (r *Robot) SendVideo(chatID string, file interface{}, options ...SendOption) (*SentMessage, error) {
+	return r.SendFile(chatID, file, "Video", options...)
+}
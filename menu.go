@@ -0,0 +1,384 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const menuPrefix = "mn:"
+
+// یک گره از درخت منو؛ گره دارای Items زیرمنو است و بقیه برگ هستند
+type MenuNode struct {
+	ID      string
+	Text    string
+	Title   string
+	Reply   string
+	Columns int
+	Items   []*MenuNode
+	Handler func(*Robot, *Message)
+
+	parent *MenuNode
+}
+
+// منوی تودرتو با دکمه برگشت و نگه‌داری گره فعلی هر کاربر در Storage
+type Menu struct {
+	Root       *MenuNode
+	Inline     bool
+	BackText   string
+	Breadcrumb bool
+
+	bot   *Robot
+	nodes map[string]*MenuNode
+}
+
+// ثبت درخت منو؛ گره‌های بدون ID بر اساس مسیرشان شناسه می‌گیرند
+func (r *Robot) NewMenu(root *MenuNode) (*Menu, error) {
+	if root == nil {
+		return nil, fmt.Errorf("menu root is nil")
+	}
+	if root.ID == "" {
+		root.ID = "main"
+	}
+
+	menu := &Menu{
+		Root:     root,
+		BackText: "🔙 برگشت",
+		bot:      r,
+		nodes:    make(map[string]*MenuNode),
+	}
+	if err := menu.index(root, nil); err != nil {
+		return nil, err
+	}
+
+	r.OnCallbackPrefix(menu.prefix(), menu.handle)
+
+	r.mu.Lock()
+	r.Menus = append(r.Menus, menu)
+	r.mu.Unlock()
+	return menu, nil
+}
+
+func (m *Menu) index(node, parent *MenuNode) error {
+	if _, exists := m.nodes[node.ID]; exists {
+		return fmt.Errorf("duplicate menu node id %q", node.ID)
+	}
+	node.parent = parent
+	m.nodes[node.ID] = node
+
+	for i, item := range node.Items {
+		if item == nil {
+			return fmt.Errorf("menu node %q has a nil item", node.ID)
+		}
+		if item.Text == "" {
+			return fmt.Errorf("menu node %q item %d has no text", node.ID, i)
+		}
+		if item.ID == "" {
+			item.ID = node.ID + "." + strconv.Itoa(i)
+		}
+		if err := m.index(item, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ساخت منو از YAML:
+//
+//	keypad: chat
+//	title: منوی اصلی
+//	columns: 2
+//	items:
+//	  - id: rating
+//	    text: ⭐ امتیازدهی
+//	    items:
+//	      - text: ⭐⭐⭐⭐⭐
+//	        reply: ممنون!
+func (r *Robot) LoadMenu(source string) (*Menu, error) {
+	parsed, err := parseYAML(source)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := parsed.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("menu must be a mapping")
+	}
+
+	root, err := menuNodeFromYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// اعتبارسنجی قبل از NewMenu که مسیر callback و منو را ثبت می‌کند
+	keypadType, _ := data["keypad"].(string)
+	if keypadType != "" && keypadType != "chat" && keypadType != "inline" {
+		return nil, fmt.Errorf("unknown keypad type %q", keypadType)
+	}
+
+	menu, err := r.NewMenu(root)
+	if err != nil {
+		return nil, err
+	}
+	menu.Inline = keypadType == "inline"
+	if back, ok := data["back"].(string); ok {
+		menu.BackText = back
+	}
+	menu.Breadcrumb = data["breadcrumb"] == "true"
+	return menu, nil
+}
+
+func menuNodeFromYAML(data map[string]interface{}) (*MenuNode, error) {
+	node := &MenuNode{}
+	node.ID, _ = data["id"].(string)
+	node.Text, _ = data["text"].(string)
+	node.Title, _ = data["title"].(string)
+	node.Reply, _ = data["reply"].(string)
+
+	if columns, ok := data["columns"].(string); ok {
+		n, err := strconv.Atoi(columns)
+		if err != nil {
+			return nil, fmt.Errorf("menu columns %q: %v", columns, err)
+		}
+		node.Columns = n
+	}
+
+	items, _ := data["items"].([]interface{})
+	for _, raw := range items {
+		itemData, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("menu item must be a mapping")
+		}
+		item, err := menuNodeFromYAML(itemData)
+		if err != nil {
+			return nil, err
+		}
+		node.Items = append(node.Items, item)
+	}
+	return node, nil
+}
+
+// تعیین هندلر برای گره‌ای که در YAML تعریف شده
+func (m *Menu) Handle(nodeID string, handler func(*Robot, *Message)) error {
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return fmt.Errorf("unknown menu node %q", nodeID)
+	}
+	node.Handler = handler
+	return nil
+}
+
+func (m *Menu) Node(nodeID string) *MenuNode {
+	return m.nodes[nodeID]
+}
+
+// مسیر از ریشه تا این گره
+func (n *MenuNode) Path() []*MenuNode {
+	var path []*MenuNode
+	for node := n; node != nil; node = node.parent {
+		path = append([]*MenuNode{node}, path...)
+	}
+	return path
+}
+
+func (n *MenuNode) Parent() *MenuNode {
+	return n.parent
+}
+
+func (m *Menu) prefix() string {
+	return menuPrefix + m.Root.ID + ":"
+}
+
+func (m *Menu) currentKey(msg *Message) string {
+	return "menu:" + m.Root.ID + ":" + msg.ChatID + ":" + msg.SenderID
+}
+
+func (m *Menu) messageKey(chatID, nonce string) string {
+	return "menu:" + m.Root.ID + ":msg:" + chatID + ":" + nonce
+}
+
+// در حالت شیشه‌ای هر پیام منو nonce خودش را در شناسه دکمه‌ها دارد: mn:<root>:<nonce>:<node>
+func (m *Menu) buttonID(nonce, nodeID string) string {
+	if !m.Inline {
+		return m.prefix() + nodeID
+	}
+	return m.prefix() + nonce + ":" + nodeID
+}
+
+// گره فعلی کاربر؛ اگر ذخیره نشده باشد ریشه منو
+func (m *Menu) Current(msg *Message) *MenuNode {
+	if id, ok, err := m.bot.storage().Get(m.currentKey(msg)); err == nil && ok {
+		if node, ok := m.nodes[id]; ok {
+			return node
+		}
+	}
+	return m.Root
+}
+
+func (m *Menu) title(node *MenuNode) string {
+	title := node.Title
+	if title == "" {
+		title = node.Text
+	}
+	if title == "" {
+		title = "📋"
+	}
+
+	if m.Breadcrumb && node.parent != nil {
+		var names []string
+		for _, n := range node.Path() {
+			if n.Text != "" {
+				names = append(names, n.Text)
+			}
+		}
+		title = strings.Join(names, " › ") + "\n\n" + title
+	}
+	return title
+}
+
+func (m *Menu) keypad(node *MenuNode, nonce string) map[string]interface{} {
+	columns := node.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+
+	var rows []map[string]interface{}
+	var row []map[string]interface{}
+	for _, item := range node.Items {
+		row = append(row, CreateInlineButton(item.Text, m.buttonID(nonce, item.ID)))
+		if len(row) == columns {
+			rows = append(rows, CreateButtonRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, CreateButtonRow(row...))
+	}
+	if node.parent != nil {
+		rows = append(rows, CreateButtonRow(CreateInlineButton(m.BackText, m.buttonID(nonce, node.parent.ID))))
+	}
+
+	keypad := CreateInlineKeypad(rows)
+	if !m.Inline {
+		keypad["resize_keyboard"] = true
+	}
+	return keypad
+}
+
+// نمایش زیرمنو؛ در حالت شیشه‌ای پیام منویی که nonce به آن تعلق دارد ویرایش می‌شود
+// و nonce خالی یعنی پیام جدید
+func (m *Menu) show(msg *Message, node *MenuNode, nonce string) error {
+	edit := nonce != ""
+	if !edit {
+		nonce = newKeypadNonce()
+	}
+	text := m.title(node)
+	keypad := m.keypad(node, nonce)
+
+	if !m.Inline {
+		_, err := m.bot.SendMessage(msg.ChatID, text, WithChatKeypad(keypad, "New"))
+		return err
+	}
+
+	key := m.messageKey(msg.ChatID, nonce)
+	if edit {
+		if messageID, ok, err := m.bot.storage().Get(key); err == nil && ok && messageID != "" {
+			result, err := m.bot.EditMessageText(msg.ChatID, messageID, text)
+			if err != nil {
+				return err
+			}
+			if isAPIError(result) {
+				return fmt.Errorf("editMessageText failed: %v", result["status"])
+			}
+			return m.bot.replaceKeypad(msg.ChatID, key, text, keypad)
+		}
+	}
+	_, err := m.bot.sendKeypad(msg.ChatID, key, text, keypad)
+	return err
+}
+
+func (m *Menu) enter(msg *Message, node *MenuNode, nonce string) {
+	var err error
+	if len(node.Items) > 0 {
+		err = m.bot.storage().Set(m.currentKey(msg), node.ID)
+		if err == nil {
+			err = m.show(msg, node, nonce)
+		}
+	} else if node.Reply != "" {
+		_, err = m.bot.SendMessage(msg.ChatID, node.Reply)
+	}
+	if err != nil {
+		m.bot.reportError(msg, fmt.Errorf("menu %q: %v", m.Root.ID, err))
+	}
+
+	if node.Handler != nil {
+		node.Handler(m.bot, msg)
+	}
+}
+
+// نمایش ریشه منو برای کاربر
+func (m *Menu) Open(msg *Message) {
+	m.enter(msg, m.Root, "")
+}
+
+func (m *Menu) OpenNode(msg *Message, nodeID string) error {
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return fmt.Errorf("unknown menu node %q", nodeID)
+	}
+	m.enter(msg, node, "")
+	return nil
+}
+
+func (m *Menu) handle(r *Robot, msg *Message) {
+	nonce, nodeID := "", strings.TrimPrefix(msg.ButtonID(), m.prefix())
+	if m.Inline {
+		parts := strings.SplitN(nodeID, ":", 2)
+		if len(parts) != 2 {
+			return
+		}
+		nonce, nodeID = parts[0], parts[1]
+	}
+	if node, ok := m.nodes[nodeID]; ok {
+		m.enter(msg, node, nonce)
+	}
+}
+
+// کیبوردهای چت متن دکمه را ارسال می‌کنند؛ تطبیق با گزینه‌های گره فعلی کاربر
+func (m *Menu) match(msg *Message) *MenuNode {
+	if m.Inline || msg.Text == "" {
+		return nil
+	}
+
+	// متن دریافتی قبل از مسیریابی یکسان‌سازی شده؛ متن دکمه‌ها هم باید همان‌طور مقایسه شوند
+	normalize := func(text string) string {
+		if m.bot.Normalize != nil {
+			return NormalizePersian(text, *m.bot.Normalize)
+		}
+		return text
+	}
+
+	current := m.Current(msg)
+	if msg.Text == normalize(m.BackText) && current.parent != nil {
+		return current.parent
+	}
+	for _, item := range current.Items {
+		if normalize(item.Text) == msg.Text {
+			return item
+		}
+	}
+	return nil
+}
+
+func (r *Robot) findMenuNode(msg *Message) (*Menu, *MenuNode) {
+	r.mu.Lock()
+	menus := make([]*Menu, len(r.Menus))
+	copy(menus, r.Menus)
+	r.mu.Unlock()
+
+	for _, menu := range menus {
+		if node := menu.match(msg); node != nil {
+			return menu, node
+		}
+	}
+	return nil, nil
+}
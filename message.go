@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// نتیجه متدهای ارسال
+type SentMessage struct {
+	ChatID    string
+	MessageID string
+	FileID    string
+	RawData   map[string]interface{}
+}
+
+func parseSentMessage(chatID string, result map[string]interface{}) (*SentMessage, error) {
+	if isAPIError(result) {
+		return nil, fmt.Errorf("request failed: %v", result["status"])
+	}
+
+	sent := &SentMessage{
+		ChatID:  chatID,
+		RawData: result,
+	}
+
+	if data, ok := result["data"].(map[string]interface{}); ok {
+		if messageID, ok := data["message_id"].(string); ok {
+			sent.MessageID = messageID
+		} else if messageID, ok := data["new_message_id"].(string); ok {
+			sent.MessageID = messageID
+		}
+		sent.FileID, _ = data["file_id"].(string)
+	}
+
+	return sent, nil
+}
+
+// اجرای متد ارسال و تبدیل پاسخ به SentMessage
+func (r *Robot) send(method, chatID string, payload map[string]interface{}) (*SentMessage, error) {
+	result, err := r.post(method, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := parseSentMessage(chatID, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if sent.FileID == "" {
+		sent.FileID, _ = payload["file_id"].(string)
+	}
+	return sent, nil
+}
+
+// ساخت Message برای پیام ارسال‌شده تا بتوان آن را ویرایش یا حذف کرد
+func (r *Robot) sentMessage(sent *SentMessage, text string) *Message {
+	message := &Message{
+		Bot:       r,
+		ChatID:    sent.ChatID,
+		MessageID: sent.MessageID,
+		Text:      text,
+		RawData:   sent.RawData,
+	}
+	if sent.FileID != "" {
+		message.File = &File{FileID: sent.FileID}
+	}
+	return message
+}
+
+// ارسال پیام در همان چت بدون ریپلای
+func (m *Message) Answer(text string, options ...SendOption) (*Message, error) {
+	sent, err := m.Bot.SendMessage(m.ChatID, text, options...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Bot.sentMessage(sent, text), nil
+}
+
+// پاسخ به همین پیام (reply_to_message_id)
+func (m *Message) Reply(text string, options ...SendOption) (*Message, error) {
+	options = append([]SendOption{WithReplyTo(m.MessageID)}, options...)
+	return m.Answer(text, options...)
+}
+
+func (m *Message) ReplyKeypad(text string, keypad map[string]interface{}, options ...SendOption) (*Message, error) {
+	options = append([]SendOption{WithInlineKeypad(keypad)}, options...)
+	return m.Reply(text, options...)
+}
+
+func (m *Message) ReplyImage(file interface{}, options ...SendOption) (*Message, error) {
+	options = append([]SendOption{WithReplyTo(m.MessageID)}, options...)
+	sent, err := m.Bot.SendImage(m.ChatID, file, options...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Bot.sentMessage(sent, ""), nil
+}
+
+func (m *Message) ReplyFile(file interface{}, mediaType string, options ...SendOption) (*Message, error) {
+	options = append([]SendOption{WithReplyTo(m.MessageID)}, options...)
+	sent, err := m.Bot.SendFile(m.ChatID, file, mediaType, options...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Bot.sentMessage(sent, ""), nil
+}
+
+func (m *Message) EditText(text string) (*Message, error) {
+	result, err := m.Bot.EditMessageText(m.ChatID, m.MessageID, text)
+	if err != nil {
+		return nil, err
+	}
+	if isAPIError(result) {
+		return nil, fmt.Errorf("editMessageText failed: %v", result["status"])
+	}
+
+	m.Text = text
+	return m, nil
+}
+
+// شناسه دکمه‌ای که این پیام callback آن است
+func (m *Message) ButtonID() string {
+	if auxData, ok := m.RawData["aux_data"].(map[string]interface{}); ok {
+		buttonID, _ := auxData["button_id"].(string)
+		return buttonID
+	}
+	return ""
+}
+
+func (m *Message) EditKeypad(keypad map[string]interface{}) error {
+	result, err := m.Bot.EditMessageKeypad(m.ChatID, m.MessageID, keypad)
+	if err != nil {
+		return err
+	}
+	if isAPIError(result) {
+		return fmt.Errorf("editMessageKeypad failed: %v", result["status"])
+	}
+	return nil
+}
+
+// شناسه کوتاه هر پیام ویجت (تقویم، لیست، منو) در شناسه دکمه‌ها و کلید Storage
+// تا چند پیام از یک ویجت در یک چت پیام همدیگر را ویرایش نکنند
+func newKeypadNonce() string {
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// ارسال پیام با کیبورد شیشه‌ای و نگه‌داشتن شناسه آن در Storage برای ویرایش‌های بعدی
+func (r *Robot) sendKeypad(chatID, key, text string, keypad map[string]interface{}, options ...SendOption) (*SentMessage, error) {
+	options = append(options, WithInlineKeypad(keypad))
+	sent, err := r.SendMessage(chatID, text, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.storage().Set(key, sent.MessageID); err != nil {
+		return sent, err
+	}
+	return sent, nil
+}
+
+// ویرایش کیبورد پیامی که با sendKeypad ارسال شده؛ اگر پیام شناخته‌شده نباشد پیام جدید ارسال می‌شود
+func (r *Robot) replaceKeypad(chatID, key, text string, keypad map[string]interface{}) error {
+	messageID, ok, err := r.storage().Get(key)
+	if err != nil {
+		return err
+	}
+	if !ok || messageID == "" {
+		_, err := r.sendKeypad(chatID, key, text, keypad)
+		return err
+	}
+
+	result, err := r.EditMessageKeypad(chatID, messageID, keypad)
+	if err != nil {
+		return err
+	}
+	if isAPIError(result) {
+		return fmt.Errorf("editMessageKeypad failed: %v", result["status"])
+	}
+	return nil
+}
+
+func (m *Message) Delete() error {
+	result, err := m.Bot.DeleteMessage(m.ChatID, m.MessageID)
+	if err != nil {
+		return err
+	}
+	if isAPIError(result) {
+		return fmt.Errorf("deleteMessage failed: %v", result["status"])
+	}
+	return nil
+}
+
+func (m *Message) ForwardTo(chatID string) (*Message, error) {
+	sent, err := m.Bot.ForwardMessage(m.ChatID, m.MessageID, chatID, false)
+	if err != nil {
+		return nil, err
+	}
+	return m.Bot.sentMessage(sent, m.Text), nil
+}
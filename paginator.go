@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const paginatorPrefix = "pg:"
+
+// یک مورد از لیست؛ ID در شناسه دکمه قرار می‌گیرد
+type PageItem struct {
+	Text string
+	ID   string
+}
+
+// منبع داده صفحه‌بندی: موارد صفحه page (از ۱) و تعداد کل موارد
+type PageSource func(page, size int) ([]PageItem, int, error)
+
+// لیست صفحه‌بندی‌شده با کیبورد شیشه‌ای و ناوبری « ‹ n/m › »
+type Paginator struct {
+	ID        string
+	PageSize  int
+	Columns   int
+	Source    PageSource
+	OnSelect  func(*Robot, *Message, string)
+	EmptyText string // متن دکمه لیست خالی؛ پیش‌فرض «موردی یافت نشد»
+
+	bot *Robot
+}
+
+// ساخت صفحه‌بند و ثبت هندلر دکمه‌های آن با پیشوند pg:<id>:
+func (r *Robot) NewPaginator(id string, pageSize int, source PageSource, onSelect func(*Robot, *Message, string)) *Paginator {
+	paginator := &Paginator{
+		ID:       id,
+		PageSize: pageSize,
+		Columns:  1,
+		Source:   source,
+		OnSelect: onSelect,
+		bot:      r,
+	}
+	r.OnCallbackPrefix(paginator.prefix(), paginator.handle)
+	return paginator
+}
+
+func (p *Paginator) prefix() string {
+	return paginatorPrefix + p.ID + ":"
+}
+
+func (p *Paginator) storageKey(chatID, nonce string) string {
+	return "paginator:" + p.ID + ":" + chatID + ":" + nonce
+}
+
+func (p *Paginator) size() int {
+	if p.PageSize <= 0 {
+		return 10
+	}
+	return p.PageSize
+}
+
+// شناسه دکمه‌ها: pg:<id>:<nonce>:<action>[:...]؛ nonce پیام ارسال‌شده را مشخص می‌کند
+func (p *Paginator) buttonID(nonce, action string) string {
+	return p.prefix() + nonce + ":" + action
+}
+
+func (p *Paginator) pageButton(nonce, text string, page int) map[string]interface{} {
+	return CreateInlineButton(text, p.buttonID(nonce, "p:"+strconv.Itoa(page)))
+}
+
+// کیبورد صفحه page؛ شماره صفحه خارج از محدوده به نزدیک‌ترین صفحه معتبر برده می‌شود
+func (p *Paginator) keypad(nonce string, page int) (map[string]interface{}, error) {
+	if p.Source == nil {
+		return nil, fmt.Errorf("paginator %q has no source", p.ID)
+	}
+
+	size := p.size()
+	if page < 1 {
+		page = 1
+	}
+	items, total, err := p.Source(page, size)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := (total + size - 1) / size
+	if pages < 1 {
+		pages = 1
+	}
+	if page > pages {
+		page = pages
+		if items, _, err = p.Source(page, size); err != nil {
+			return nil, err
+		}
+	}
+
+	columns := p.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+
+	var rows []map[string]interface{}
+	var row []map[string]interface{}
+	for _, item := range items {
+		row = append(row, CreateInlineButton(item.Text, p.buttonID(nonce, "i:"+strconv.Itoa(page)+":"+item.ID)))
+		if len(row) == columns {
+			rows = append(rows, CreateButtonRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, CreateButtonRow(row...))
+	}
+	// کیبورد بدون سطر ({"rows": null}) توسط سرور پذیرفته نمی‌شود
+	if len(rows) == 0 {
+		text := p.EmptyText
+		if text == "" {
+			text = "موردی یافت نشد"
+		}
+		rows = append(rows, CreateButtonRow(CreateInlineButton(text, p.buttonID(nonce, "x"))))
+	}
+
+	if pages > 1 {
+		var nav []map[string]interface{}
+		if page > 1 {
+			nav = append(nav, p.pageButton(nonce, "«", 1), p.pageButton(nonce, "‹", page-1))
+		}
+		nav = append(nav, CreateInlineButton(fmt.Sprintf("%d/%d", page, pages), p.buttonID(nonce, "x")))
+		if page < pages {
+			nav = append(nav, p.pageButton(nonce, "›", page+1), p.pageButton(nonce, "»", pages))
+		}
+		rows = append(rows, CreateButtonRow(nav...))
+	}
+
+	return CreateInlineKeypad(rows), nil
+}
+
+// ارسال پیام همراه با صفحه page از لیست
+func (p *Paginator) Send(chatID, text string, page int, options ...SendOption) (*SentMessage, error) {
+	nonce := newKeypadNonce()
+	keypad, err := p.keypad(nonce, page)
+	if err != nil {
+		return nil, err
+	}
+	return p.bot.sendKeypad(chatID, p.storageKey(chatID, nonce), text, keypad, options...)
+}
+
+func (p *Paginator) handle(r *Robot, m *Message) {
+	parts := strings.SplitN(strings.TrimPrefix(m.ButtonID(), p.prefix()), ":", 4)
+	if len(parts) < 2 {
+		return
+	}
+	nonce := parts[0]
+
+	switch {
+	case parts[1] == "p" && len(parts) == 3:
+		page, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return
+		}
+		keypad, err := p.keypad(nonce, page)
+		if err == nil {
+			err = r.replaceKeypad(m.ChatID, p.storageKey(m.ChatID, nonce), "📋", keypad)
+		}
+		if err != nil {
+			r.reportError(m, fmt.Errorf("paginator %q: %v", p.ID, err))
+		}
+
+	case parts[1] == "i" && len(parts) == 4:
+		if p.OnSelect != nil {
+			p.OnSelect(r, m, parts[3])
+		}
+	}
+}
+
+// منبع داده برای یک slice ثابت
+func SliceSource(items []PageItem) PageSource {
+	return func(page, size int) ([]PageItem, int, error) {
+		start := (page - 1) * size
+		if start < 0 || start >= len(items) {
+			return nil, len(items), nil
+		}
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		return items[start:end], len(items), nil
+	}
+}
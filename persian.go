@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	persianDigits = "۰۱۲۳۴۵۶۷۸۹"
+	arabicDigits  = "٠١٢٣٤٥٦٧٨٩"
+)
+
+// تنظیمات یکسان‌سازی متن فارسی
+type NormalizeOptions struct {
+	UnifyCharacters bool
+	StripZWNJ       bool
+	StripDiacritics bool
+	LatinDigits     bool
+	CollapseSpaces  bool
+}
+
+// نیم‌فاصله به صورت پیش‌فرض حفظ می‌شود چون متن پیام‌ها گاهی عیناً به کاربر برگردانده می‌شود
+var DefaultNormalizeOptions = NormalizeOptions{
+	UnifyCharacters: true,
+	StripDiacritics: true,
+	LatinDigits:     true,
+	CollapseSpaces:  true,
+}
+
+// اعمال یکسان‌سازی روی متن پیام‌ها قبل از مسیریابی دستورات و هندلرها
+func WithTextNormalization(options NormalizeOptions) func(*Robot) {
+	return func(r *Robot) {
+		r.Normalize = &options
+	}
+}
+
+var arabicToPersian = map[rune]rune{
+	'ي': 'ی',
+	'ى': 'ی',
+	'ك': 'ک',
+	'ة': 'ه',
+}
+
+func isDiacritic(ch rune) bool {
+	return (ch >= 0x064B && ch <= 0x065F) || ch == 0x0670 || ch == 0x0640
+}
+
+// ZWJ (U+200D) حذف نمی‌شود چون بخشی از ایموجی‌های ترکیبی است
+func isZeroWidth(ch rune) bool {
+	return ch == 0x200C || ch == 0x200B || ch == 0xFEFF
+}
+
+// یکسان‌سازی حروف عربی و فارسی، حذف نیم‌فاصله و اعراب و تبدیل ارقام طبق تنظیمات
+func NormalizePersian(text string, options NormalizeOptions) string {
+	var b strings.Builder
+	for _, ch := range text {
+		if options.UnifyCharacters {
+			if replacement, ok := arabicToPersian[ch]; ok {
+				ch = replacement
+			}
+		}
+		if options.StripZWNJ && isZeroWidth(ch) {
+			continue
+		}
+		if options.StripDiacritics && isDiacritic(ch) {
+			continue
+		}
+		if options.LatinDigits {
+			ch = latinDigit(ch)
+		}
+		b.WriteRune(ch)
+	}
+
+	if options.CollapseSpaces {
+		return collapseSpaces(b.String())
+	}
+	return b.String()
+}
+
+// فاصله‌های افقی پشت‌سرهم یکی می‌شوند ولی خطوط جدید پیام‌های چندخطی (آدرس، گزارش) حفظ می‌شوند
+func collapseSpaces(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func latinDigit(ch rune) rune {
+	if ch >= '۰' && ch <= '۹' {
+		return '0' + ch - '۰'
+	}
+	if ch >= '٠' && ch <= '٩' {
+		return '0' + ch - '٠'
+	}
+	return ch
+}
+
+// تبدیل ارقام فارسی و عربی به لاتین
+func ToLatinDigits(text string) string {
+	return strings.Map(latinDigit, text)
+}
+
+// خواندن عدد صحیح از ورودی کاربر با ارقام فارسی، عربی یا لاتین و جداکننده هزارگان
+func ParseNumber(text string) (int64, error) {
+	text = strings.TrimSpace(ToLatinDigits(text))
+	text = strings.NewReplacer(",", "", "٬", "", "،", "", " ", "").Replace(text)
+	return strconv.ParseInt(text, 10, 64)
+}
+
+func ToArabicDigits(text string) string {
+	var b strings.Builder
+	for _, ch := range text {
+		if ch >= '0' && ch <= '9' {
+			b.WriteRune([]rune(arabicDigits)[ch-'0'])
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+// تبدیل ارقام لاتین به فارسی
+func ToPersianDigits(text string) string {
+	var b strings.Builder
+	for _, ch := range text {
+		if ch >= '0' && ch <= '9' {
+			b.WriteRune([]rune(persianDigits)[ch-'0'])
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+// جداکردن سه‌رقمی عدد با جداکننده دلخواه
+func groupDigits(number string, separator string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	fraction := ""
+	if dot := strings.IndexByte(number, '.'); dot >= 0 {
+		number, fraction = number[:dot], number[dot:]
+	}
+
+	var b strings.Builder
+	for i, ch := range number {
+		if i > 0 && (len(number)-i)%3 == 0 {
+			b.WriteString(separator)
+		}
+		b.WriteRune(ch)
+	}
+	return sign + b.String() + fraction
+}
+
+// قالب‌بندی عدد با ارقام فارسی و جداکننده هزارگان، مثل ۱٬۲۵۰٬۰۰۰
+func FormatPersianNumber(number interface{}) string {
+	return ToPersianDigits(groupDigits(toString(number), "٬"))
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type UploadProgress struct {
+	Sent    int64
+	Total   int64
+	Rate    float64
+	Elapsed time.Duration
+	Done    bool
+}
+
+type ProgressFunc func(UploadProgress)
+
+type UploadOption func(*uploadConfig)
+
+type uploadConfig struct {
+	progress ProgressFunc
+	interval time.Duration
+}
+
+func WithProgress(progress ProgressFunc) UploadOption {
+	return func(c *uploadConfig) {
+		c.progress = progress
+	}
+}
+
+func WithProgressInterval(interval time.Duration) UploadOption {
+	return func(c *uploadConfig) {
+		c.interval = interval
+	}
+}
+
+func newUploadConfig(options []UploadOption) *uploadConfig {
+	config := &uploadConfig{
+		interval: 500 * time.Millisecond,
+	}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+type progressReader struct {
+	reader   io.Reader
+	total    int64
+	sent     int64
+	started  time.Time
+	last     time.Time
+	interval time.Duration
+	progress ProgressFunc
+}
+
+func newProgressReader(reader io.Reader, total int64, config *uploadConfig) *progressReader {
+	now := time.Now()
+	return &progressReader{
+		reader:   reader,
+		total:    total,
+		started:  now,
+		last:     now,
+		interval: config.interval,
+		progress: config.progress,
+	}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.sent += int64(n)
+
+	now := time.Now()
+	if err == io.EOF {
+		p.report(now, true)
+	} else if now.Sub(p.last) >= p.interval {
+		p.last = now
+		p.report(now, false)
+	}
+
+	return n, err
+}
+
+func (p *progressReader) report(now time.Time, done bool) {
+	elapsed := now.Sub(p.started)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.sent) / elapsed.Seconds()
+	}
+
+	p.progress(UploadProgress{
+		Sent:    p.sent,
+		Total:   p.total,
+		Rate:    rate,
+		Elapsed: elapsed,
+		Done:    done,
+	})
+}
+
+// بدنه آپلود و دانلود ممکن است بسیار طولانی باشد؛ Timeout کلاینت فقط برای فراخوانی‌های API است
+// و مدت انتقال را context (یا در نبود مهلت، stallGuard) تعیین می‌کند
+func (r *Robot) transferClient() *http.Client {
+	if r.Client.Timeout == 0 {
+		return r.Client
+	}
+
+	client := *r.Client
+	client.Timeout = 0
+	return &client
+}
+
+// لغو انتقالی که به اندازه Client.Timeout هیچ داده‌ای جابه‌جا نکرده باشد
+type stallGuard struct {
+	timeout time.Duration
+	timer   *time.Timer
+	mu      sync.Mutex
+	stalled bool
+}
+
+// اگر ctx مهلت نداشته باشد (مثلاً SendFile با context.Background) انتقال متوقف‌شده
+// بعد از Client.Timeout بدون پیشرفت لغو می‌شود؛ guard در غیر این صورت nil است
+func (r *Robot) transferContext(ctx context.Context) (context.Context, *stallGuard, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if _, ok := ctx.Deadline(); ok || r.Client.Timeout <= 0 {
+		return ctx, nil, cancel
+	}
+
+	guard := &stallGuard{timeout: r.Client.Timeout}
+	guard.timer = time.AfterFunc(guard.timeout, func() {
+		guard.mu.Lock()
+		guard.stalled = true
+		guard.mu.Unlock()
+		cancel()
+	})
+	return ctx, guard, func() {
+		guard.timer.Stop()
+		cancel()
+	}
+}
+
+func (g *stallGuard) reader(reader io.Reader) io.Reader {
+	if g == nil {
+		return reader
+	}
+	return &stallReader{reader: reader, guard: g}
+}
+
+// خطای لغو به خطای توقف انتقال تبدیل می‌شود تا دلیل آن روشن باشد
+func (g *stallGuard) err(err error) error {
+	if g == nil || err == nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stalled {
+		return fmt.Errorf("transfer stalled: no progress for %s: %w", g.timeout, err)
+	}
+	return err
+}
+
+type stallReader struct {
+	reader io.Reader
+	guard  *stallGuard
+}
+
+func (s *stallReader) Read(b []byte) (int, error) {
+	n, err := s.reader.Read(b)
+	if n > 0 {
+		s.guard.timer.Reset(s.guard.timeout)
+	}
+	return n, err
+}
+
+func (r *Robot) UploadFileContext(ctx context.Context, filePath, mediaType string, options ...UploadOption) (string, error) {
+	return r.UploadInput(ctx, filePath, mediaType, options...)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func FormatProgress(p UploadProgress) string {
+	if p.Done {
+		return fmt.Sprintf("✅ آپلود کامل شد (%s)", formatBytes(p.Sent))
+	}
+
+	rate := formatBytes(int64(p.Rate)) + "/s"
+	if p.Total <= 0 {
+		return fmt.Sprintf("⏳ در حال آپلود... %s - %s", formatBytes(p.Sent), rate)
+	}
+
+	percent := float64(p.Sent) * 100 / float64(p.Total)
+	return fmt.Sprintf("⏳ در حال آپلود... %.0f%% (%s / %s) - %s",
+		percent, formatBytes(p.Sent), formatBytes(p.Total), rate)
+}
+
+// گزارش پیشرفت با ویرایش دوره‌ای یک پیام وضعیت؛ ویرایش‌ها در goroutine جدا انجام می‌شوند
+// تا آپلود منتظر API نماند و تا وقتی ویرایشی در جریان است گزارش‌های میانی کنار گذاشته می‌شوند.
+// stop گزارش‌های باقی‌مانده را دور می‌ریزد و منتظر ویرایش در جریان می‌ماند؛ قبل از ویرایش نهایی پیام صدا زده شود
+func (r *Robot) ProgressReporter(chatID, messageID string, interval time.Duration) (progress ProgressFunc, stop func()) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var last time.Time
+	var busy, stopped bool
+	var pending *UploadProgress
+
+	var edit func(p UploadProgress)
+	edit = func(p UploadProgress) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			mu.Lock()
+			skip := stopped
+			mu.Unlock()
+			if !skip {
+				r.EditMessageText(chatID, messageID, FormatProgress(p))
+			}
+
+			mu.Lock()
+			next := pending
+			pending = nil
+			if stopped {
+				next = nil
+			}
+			busy = next != nil
+			mu.Unlock()
+
+			if next != nil {
+				edit(*next)
+			}
+		}()
+	}
+
+	progress = func(p UploadProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if stopped || (!p.Done && time.Since(last) < interval) {
+			return
+		}
+		if busy {
+			// گزارش پایانی بعد از ویرایش در جریان ارسال می‌شود
+			if p.Done {
+				pending = &p
+			}
+			return
+		}
+		busy = true
+		last = time.Now()
+		edit(p)
+	}
+
+	stop = func() {
+		mu.Lock()
+		stopped = true
+		pending = nil
+		mu.Unlock()
+		wg.Wait()
+	}
+	return progress, stop
+}
+
+// ارسال فایل همراه با پیام وضعیت که درصد پیشرفت آپلود را نشان می‌دهد
+func (r *Robot) SendFileWithProgress(ctx context.Context, chatID string, file interface{}, mediaType string, options ...SendOption) (*SentMessage, error) {
+	status, err := r.SendMessage(chatID, "⏳ در حال آپلود...")
+	if err != nil {
+		return nil, err
+	}
+
+	statusID := status.MessageID
+
+	var uploadOptions []UploadOption
+	stopProgress := func() {}
+	if statusID != "" {
+		var progress ProgressFunc
+		progress, stopProgress = r.ProgressReporter(chatID, statusID, 2*time.Second)
+		uploadOptions = append(uploadOptions, WithProgress(progress))
+	}
+
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+	}
+
+	if err := r.applySendOptions(payload, options); err != nil {
+		stopProgress()
+		return nil, err
+	}
+
+	var sent *SentMessage
+	if r.FileCache {
+		sent, err = r.sendFileCached(ctx, chatID, file, mediaType, payload, uploadOptions...)
+	} else {
+		var fileID string
+		fileID, err = r.UploadInput(ctx, file, mediaType, uploadOptions...)
+		if err == nil {
+			payload["file_id"] = fileID
+			sent, err = r.send("sendFile", chatID, payload)
+		}
+	}
+
+	// ویرایش‌های پیشرفتِ در جریان نباید بعد از وضعیت نهایی برسند و آن را بازنویسی کنند؛
+	// با فایل کش‌شده چیزی خوانده نمی‌شود و گزارش پایانی پیشرفت هم نمی‌آید
+	stopProgress()
+	if statusID != "" {
+		if err != nil {
+			r.EditMessageText(chatID, statusID, fmt.Sprintf("❌ آپلود ناموفق بود: %v", err))
+		} else {
+			r.EditMessageText(chatID, statusID, "✅ فایل ارسال شد")
+		}
+	}
+	return sent, err
+}
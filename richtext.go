@@ -0,0 +1,481 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+type ParseMode string
+
+const (
+	ParseModeMarkdown ParseMode = "Markdown"
+	ParseModeHTML     ParseMode = "HTML"
+)
+
+// یک بخش قالب‌بندی در متادیتای پیام؛ ایندکس‌ها بر حسب واحدهای UTF-16 هستند
+type MetadataPart struct {
+	Type        string
+	FromIndex   int
+	Length      int
+	LinkURL     string
+	MentionGUID string
+}
+
+func (p MetadataPart) toMap() map[string]interface{} {
+	part := map[string]interface{}{
+		"type":       p.Type,
+		"from_index": p.FromIndex,
+		"length":     p.Length,
+	}
+	if p.LinkURL != "" {
+		part["link_url"] = p.LinkURL
+	}
+	if p.MentionGUID != "" {
+		part["mention_text_object_guid"] = p.MentionGUID
+		part["mention_text_object_type"] = "User"
+	}
+	return part
+}
+
+func metadataPayload(parts []MetadataPart) map[string]interface{} {
+	metaParts := make([]map[string]interface{}, len(parts))
+	for i, part := range parts {
+		metaParts[i] = part.toMap()
+	}
+	return map[string]interface{}{
+		"meta_data_parts": metaParts,
+	}
+}
+
+func WithParseMode(mode ParseMode) SendOption {
+	return func(o *SendOptions) error {
+		o.ParseMode = mode
+		return nil
+	}
+}
+
+// حالت پیش‌فرض قالب‌بندی برای همه پیام‌ها و کپشن‌ها
+func WithDefaultParseMode(mode ParseMode) func(*Robot) {
+	return func(r *Robot) {
+		r.ParseMode = mode
+	}
+}
+
+// تبدیل متن قالب‌دار به متن ساده و متادیتای روبیکا
+func ParseText(text string, mode ParseMode) (string, []MetadataPart, error) {
+	switch mode {
+	case ParseModeMarkdown:
+		return ParseMarkdown(text)
+	case ParseModeHTML:
+		return ParseHTML(text)
+	case "":
+		return text, nil, nil
+	}
+	return "", nil, fmt.Errorf("unknown parse mode %q", mode)
+}
+
+const markdownSpecial = "\\*_~|`[]()"
+
+// فرار دادن کاراکترهای خاص Markdown در متن ورودی کاربر
+func Escape(text string) string {
+	var b strings.Builder
+	for _, ch := range text {
+		if strings.ContainsRune(markdownSpecial, ch) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(ch)
+	}
+	return b.String()
+}
+
+func EscapeHTML(text string) string {
+	return html.EscapeString(text)
+}
+
+// سازنده متن خروجی که طول را بر حسب UTF-16 نگه می‌دارد
+type richTextBuilder struct {
+	text   strings.Builder
+	offset int
+	parts  []MetadataPart
+}
+
+func (b *richTextBuilder) writeRune(ch rune) {
+	b.text.WriteRune(ch)
+	b.offset += len(utf16.Encode([]rune{ch}))
+}
+
+func (b *richTextBuilder) writeString(s string) {
+	for _, ch := range s {
+		b.writeRune(ch)
+	}
+}
+
+func (b *richTextBuilder) addPart(part MetadataPart, start int) {
+	part.FromIndex = start
+	part.Length = b.offset - start
+	if part.Length <= 0 {
+		return
+	}
+	// مثلاً ***x*** یا <b><strong>x</strong></b> دو بخش یکسان می‌سازند
+	for _, existing := range b.parts {
+		if existing == part {
+			return
+		}
+	}
+	b.parts = append(b.parts, part)
+}
+
+func (b *richTextBuilder) result() (string, []MetadataPart) {
+	sort.SliceStable(b.parts, func(i, j int) bool {
+		return b.parts[i].FromIndex < b.parts[j].FromIndex
+	})
+	return b.text.String(), b.parts
+}
+
+func linkPart(url string) MetadataPart {
+	if strings.HasPrefix(url, "mention:") {
+		return MetadataPart{Type: "MentionText", MentionGUID: strings.TrimPrefix(url, "mention:")}
+	}
+	return MetadataPart{Type: "Link", LinkURL: url}
+}
+
+var markdownToggles = []struct {
+	marker   string
+	partType string
+}{
+	{"||", "Spoiler"},
+	{"**", "Bold"},
+	{"__", "Underline"},
+	{"~~", "Strike"},
+	{"*", "Bold"},
+	{"_", "Italic"},
+	{"~", "Strike"},
+}
+
+func isWordRune(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
+
+// پارس Markdown: *ضخیم*، _کج_، __زیرخط__، ~خط‌خورده~، ||اسپویلر||، `کد`، ```بلوک کد```،
+// [متن](https://link) و [نام](mention:GUID)؛ کاراکترهای خاص با \ فرار داده می‌شوند.
+// * و _ فقط در مرز کلمه علامت هستند (snake_case و 2*3 دست نمی‌خورند) و علامت، ` یا [ ] بدون جفت متن عادی است
+func ParseMarkdown(text string) (string, []MetadataPart, error) {
+	b := &richTextBuilder{}
+	runes := []rune(text)
+	open := map[string]int{}
+	var links []int
+
+	hasPrefix := func(i int, prefix string) bool {
+		for _, ch := range prefix {
+			if i >= len(runes) || runes[i] != ch {
+				return false
+			}
+			i++
+		}
+		return true
+	}
+
+	// مرزها برای کل رشته علامت‌های یکسان (مثل *** یا __) سنجیده می‌شوند، نه تک‌تک آن‌ها
+	run := func(i int) (int, int) {
+		start, end := i, i
+		for start > 0 && runes[start-1] == runes[i] {
+			start--
+		}
+		for end < len(runes) && runes[end] == runes[i] {
+			end++
+		}
+		return start, end
+	}
+	wordBounded := func(marker string) bool {
+		return marker[0] == '*' || marker[0] == '_'
+	}
+	canOpen := func(i int, marker string) bool {
+		start, end := run(i)
+		if end >= len(runes) || unicode.IsSpace(runes[end]) {
+			return false
+		}
+		return !wordBounded(marker) || start == 0 || !isWordRune(runes[start-1])
+	}
+	canClose := func(i int, marker string) bool {
+		start, end := run(i)
+		if start == 0 || unicode.IsSpace(runes[start-1]) {
+			return false
+		}
+		return !wordBounded(marker) || end >= len(runes) || !isWordRune(runes[end])
+	}
+	// پایان بلوک کد از i (که با ` شروع می‌شود) یا -1 اگر بسته نشود
+	codeEnd := func(i int) int {
+		marker := "`"
+		if hasPrefix(i, "```") {
+			marker = "```"
+		}
+		for j := i + len(marker); j < len(runes); j++ {
+			if hasPrefix(j, marker) {
+				return j + len(marker)
+			}
+		}
+		return -1
+	}
+	hasCloser := func(i int, marker string) bool {
+		for j := i + len(marker); j < len(runes); j++ {
+			switch {
+			case runes[j] == '\\':
+				j++
+				continue
+			case runes[j] == '`':
+				if end := codeEnd(j); end > 0 {
+					j = end - 1
+					continue
+				}
+			}
+			if hasPrefix(j, marker) && canClose(j, marker) {
+				return true
+			}
+		}
+		return false
+	}
+	// پایان آدرس لینک که از i شروع می‌شود؛ پرانتزهای متوازن جزو آدرس هستند: https://x.y/a_(b)
+	urlEnd := func(i int) int {
+		depth := 0
+		for j := i; j < len(runes); j++ {
+			switch runes[j] {
+			case '\\':
+				j++
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					return j
+				}
+				depth--
+			}
+		}
+		return -1
+	}
+	// [ فقط وقتی شروع لینک است که ](آدرس) متناظرش وجود داشته باشد
+	hasLinkCloser := func(i int) bool {
+		depth := 0
+		for j := i + 1; j < len(runes); j++ {
+			switch runes[j] {
+			case '\\':
+				j++
+			case '`':
+				if end := codeEnd(j); end > 0 {
+					j = end - 1
+				}
+			case '[':
+				depth++
+			case ']':
+				if depth > 0 {
+					depth--
+					continue
+				}
+				return hasPrefix(j, "](") && urlEnd(j+2) >= 0
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+
+		if ch == '\\' && i+1 < len(runes) {
+			b.writeRune(runes[i+1])
+			i += 2
+			continue
+		}
+
+		if ch == '`' {
+			marker, partType := "`", "Mono"
+			if hasPrefix(i, "```") {
+				marker, partType = "```", "Pre"
+			}
+			end := codeEnd(i)
+			if end < 0 {
+				// ` بدون جفت متن عادی است
+				b.writeString(marker)
+				i += len(marker)
+				continue
+			}
+			code := string(runes[i+len(marker) : end-len(marker)])
+			i = end
+
+			if partType == "Pre" {
+				// حذف خط اول در صورتی که فقط نام زبان باشد
+				if nl := strings.IndexByte(code, '\n'); nl >= 0 && !strings.ContainsAny(code[:nl], " \t") {
+					code = code[nl+1:]
+				}
+				code = strings.TrimSuffix(code, "\n")
+			}
+			start := b.offset
+			b.writeString(code)
+			b.addPart(MetadataPart{Type: partType}, start)
+			continue
+		}
+
+		if ch == '[' && hasLinkCloser(i) {
+			links = append(links, b.offset)
+			i++
+			continue
+		}
+
+		if ch == ']' && len(links) > 0 && hasPrefix(i, "](") && urlEnd(i+2) >= 0 {
+			end := urlEnd(i + 2)
+			url := strings.ReplaceAll(string(runes[i+2:end]), "\\", "")
+			start := links[len(links)-1]
+			links = links[:len(links)-1]
+			b.addPart(linkPart(url), start)
+			i = end + 1
+			continue
+		}
+
+		matched := false
+		for _, toggle := range markdownToggles {
+			if !hasPrefix(i, toggle.marker) {
+				continue
+			}
+			if start, ok := open[toggle.marker]; ok {
+				if !canClose(i, toggle.marker) {
+					continue
+				}
+				b.addPart(MetadataPart{Type: toggle.partType}, start)
+				delete(open, toggle.marker)
+			} else {
+				if !canOpen(i, toggle.marker) || !hasCloser(i, toggle.marker) {
+					continue
+				}
+				open[toggle.marker] = b.offset
+			}
+			i += len(toggle.marker)
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+
+		// علامتی که نقش قالب‌بندی ندارد همراه با تکرارهایش عیناً نوشته می‌شود
+		if strings.ContainsRune("*_~|", ch) {
+			for i < len(runes) && runes[i] == ch {
+				b.writeRune(ch)
+				i++
+			}
+			continue
+		}
+
+		b.writeRune(ch)
+		i++
+	}
+
+	for marker := range open {
+		return "", nil, fmt.Errorf("unclosed %q entity", marker)
+	}
+
+	plain, parts := b.result()
+	return plain, parts, nil
+}
+
+var htmlTags = map[string]string{
+	"b":       "Bold",
+	"strong":  "Bold",
+	"i":       "Italic",
+	"em":      "Italic",
+	"u":       "Underline",
+	"ins":     "Underline",
+	"s":       "Strike",
+	"del":     "Strike",
+	"strike":  "Strike",
+	"code":    "Mono",
+	"pre":     "Pre",
+	"spoiler": "Spoiler",
+	"a":       "Link",
+}
+
+// پارس HTML با تگ‌های b، i، u، s، code، pre، spoiler و a (href="mention:GUID" برای منشن)
+func ParseHTML(text string) (string, []MetadataPart, error) {
+	b := &richTextBuilder{}
+
+	type openTag struct {
+		name  string
+		start int
+		href  string
+	}
+	var stack []openTag
+
+	for len(text) > 0 {
+		lt := strings.IndexByte(text, '<')
+		if lt < 0 {
+			b.writeString(html.UnescapeString(text))
+			break
+		}
+		b.writeString(html.UnescapeString(text[:lt]))
+		text = text[lt:]
+
+		gt := strings.IndexByte(text, '>')
+		if gt < 0 {
+			return "", nil, fmt.Errorf("unclosed tag")
+		}
+		tag := strings.TrimSpace(text[1:gt])
+		text = text[gt+1:]
+
+		closing := strings.HasPrefix(tag, "/")
+		tag = strings.TrimPrefix(tag, "/")
+		name := strings.ToLower(tag)
+		attrs := ""
+		if sp := strings.IndexAny(tag, " \t\n"); sp >= 0 {
+			name = strings.ToLower(tag[:sp])
+			attrs = tag[sp+1:]
+		}
+
+		if name == "br" || name == "br/" {
+			b.writeRune('\n')
+			continue
+		}
+
+		partType, ok := htmlTags[name]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported tag <%s>", name)
+		}
+
+		if !closing {
+			stack = append(stack, openTag{name: name, start: b.offset, href: htmlAttr(attrs, "href")})
+			continue
+		}
+
+		if len(stack) == 0 || stack[len(stack)-1].name != name {
+			return "", nil, fmt.Errorf("unexpected closing tag </%s>", name)
+		}
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if partType == "Link" {
+			b.addPart(linkPart(top.href), top.start)
+		} else {
+			b.addPart(MetadataPart{Type: partType}, top.start)
+		}
+	}
+
+	if len(stack) > 0 {
+		return "", nil, fmt.Errorf("unclosed tag <%s>", stack[len(stack)-1].name)
+	}
+
+	plain, parts := b.result()
+	return plain, parts, nil
+}
+
+func htmlAttr(attrs, key string) string {
+	for _, quote := range []string{`"`, `'`} {
+		prefix := key + "=" + quote
+		if i := strings.Index(attrs, prefix); i >= 0 {
+			rest := attrs[i+len(prefix):]
+			if end := strings.Index(rest, quote); end >= 0 {
+				return html.UnescapeString(rest[:end])
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		text  string
+		parts []MetadataPart
+	}{
+		{"plain", "سلام", "سلام", nil},
+		{"bold", "*a* b", "a b", []MetadataPart{{Type: "Bold", FromIndex: 0, Length: 1}}},
+		{"italic and underline", "_a_ __b__", "a b", []MetadataPart{
+			{Type: "Italic", FromIndex: 0, Length: 1},
+			{Type: "Underline", FromIndex: 2, Length: 1},
+		}},
+		{"strike and spoiler", "~a~ ||b||", "a b", []MetadataPart{
+			{Type: "Strike", FromIndex: 0, Length: 1},
+			{Type: "Spoiler", FromIndex: 2, Length: 1},
+		}},
+		{"triple star", "***x***", "x", []MetadataPart{{Type: "Bold", FromIndex: 0, Length: 1}}},
+		{"snake case", "snake_case_name", "snake_case_name", nil},
+		{"multiplication", "2*3", "2*3", nil},
+		{"unclosed", "*a b", "*a b", nil},
+		{"escape", `\*a\*`, "*a*", nil},
+		{"mono", "`x*y`", "x*y", []MetadataPart{{Type: "Mono", FromIndex: 0, Length: 3}}},
+		{"pre with language", "```go\nx := 1\n```", "x := 1", []MetadataPart{{Type: "Pre", FromIndex: 0, Length: 6}}},
+		{"link", "[x](https://x.y)", "x", []MetadataPart{{Type: "Link", FromIndex: 0, Length: 1, LinkURL: "https://x.y"}}},
+		{"link with parentheses", "[x](https://x.y/a_(b)) c", "x c", []MetadataPart{{Type: "Link", FromIndex: 0, Length: 1, LinkURL: "https://x.y/a_(b)"}}},
+		{"mention", "[علی](mention:u0abc)", "علی", []MetadataPart{{Type: "MentionText", FromIndex: 0, Length: 3, MentionGUID: "u0abc"}}},
+		{"utf16 offsets", "😀 *a*", "😀 a", []MetadataPart{{Type: "Bold", FromIndex: 3, Length: 1}}},
+		{"intraword double star", "**a**b", "**a**b", nil},
+		{"intraword underscore run", "a__b__c", "a__b__c", nil},
+		{"bracket without link", "see [1] here", "see [1] here", nil},
+		{"lone closing bracket", "a ] b", "a ] b", nil},
+		{"unclosed link text", "[x y", "[x y", nil},
+		{"unclosed link url", "[x](https://x.y", "[x](https://x.y", nil},
+		{"nested brackets in link", "[a [b] c](u)", "a [b] c", []MetadataPart{{Type: "Link", FromIndex: 0, Length: 7, LinkURL: "u"}}},
+		{"lone backtick", "a ` b", "a ` b", nil},
+		{"unclosed pre", "```go", "```go", nil},
+		{"marker inside code", "*a `b*` c*", "a b* c", []MetadataPart{
+			{Type: "Bold", FromIndex: 0, Length: 6},
+			{Type: "Mono", FromIndex: 2, Length: 2},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, parts, err := ParseMarkdown(tt.input)
+			if err != nil {
+				t.Fatalf("ParseMarkdown(%q) error: %v", tt.input, err)
+			}
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if len(parts) != 0 || len(tt.parts) != 0 {
+				if !reflect.DeepEqual(parts, tt.parts) {
+					t.Errorf("parts = %+v, want %+v", parts, tt.parts)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		text  string
+		parts []MetadataPart
+	}{
+		{"bold", "<b>a</b> b", "a b", []MetadataPart{{Type: "Bold", FromIndex: 0, Length: 1}}},
+		{"nested identical", "<b><strong>a</strong></b>", "a", []MetadataPart{{Type: "Bold", FromIndex: 0, Length: 1}}},
+		{"entities", "a &lt;b&gt; &amp;", "a <b> &", nil},
+		{"link", `<a href="https://x.y">x</a>`, "x", []MetadataPart{{Type: "Link", FromIndex: 0, Length: 1, LinkURL: "https://x.y"}}},
+		{"mention", `<a href="mention:u0abc">x</a>`, "x", []MetadataPart{{Type: "MentionText", FromIndex: 0, Length: 1, MentionGUID: "u0abc"}}},
+		{"line break", "a<br>b", "a\nb", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, parts, err := ParseHTML(tt.input)
+			if err != nil {
+				t.Fatalf("ParseHTML(%q) error: %v", tt.input, err)
+			}
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if len(parts) != 0 || len(tt.parts) != 0 {
+				if !reflect.DeepEqual(parts, tt.parts) {
+					t.Errorf("parts = %+v, want %+v", parts, tt.parts)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHTMLErrors(t *testing.T) {
+	for _, input := range []string{"<b>a", "<x>a</x>", "<b>a</i>"} {
+		if _, _, err := ParseHTML(input); err == nil {
+			t.Errorf("ParseHTML(%q) expected error", input)
+		}
+	}
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	for _, input := range []string{"*a_b*", "[x](y)", "`~|"} {
+		text, _, err := ParseMarkdown(Escape(input))
+		if err != nil || text != input {
+			t.Errorf("ParseMarkdown(Escape(%q)) = %q, %v", input, text, err)
+		}
+		text, _, err = ParseHTML(EscapeHTML(input + "<&>"))
+		if err != nil || text != input+"<&>" {
+			t.Errorf("ParseHTML(EscapeHTML(%q)) = %q, %v", input, text, err)
+		}
+	}
+}
@@ -2,13 +2,12 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"regexp"
 	"sync"
 	"time"
 )
@@ -19,7 +18,13 @@ type Message struct {
 	MessageID string
 	SenderID  string
 	Text      string
+	Time      time.Time
+	File      *File
+	Matches   []string
+	Params    map[string]string
 	RawData   map[string]interface{}
+	stopped   bool
+	update    map[string]interface{}
 }
 
 type InlineMessage struct {
@@ -38,16 +43,36 @@ type Robot struct {
 	Client             *http.Client
 	MessageHandler     func(*Robot, *Message)
 	CallbackHandlers   []CallbackHandler
+	CommandHandlers    []CommandHandler
 	InlineQueryHandler func(*Robot, *InlineMessage)
 	WebhookURL         string
 	WebhookServer      *http.Server
 	mu                 sync.Mutex
 	IsWebhook          bool
 	PHPWebhookURL      string
+	AutoCommands       bool
+	Storage            Storage
+	FileCache          bool
+	ImageOptions       *ImageOptions
+	UploadConcurrency  int
+	ParseMode          ParseMode
+	AutoSplit          bool
+	Templates          *Templates
+	I18n               *I18n
+	Normalize          *NormalizeOptions
+	Menus              []*Menu
+	CallbackCodec      *CallbackCodec
+	routes             []messageRoute
+	lastHandlerID      HandlerID
+	ErrorHandler       func(context.Context, map[string]interface{}, error)
+	ErrorReply         string
 }
 
 type CallbackHandler struct {
 	ButtonID string
+	Prefix   bool
+	Pattern  *regexp.Regexp
+	Priority int
 	Handler  func(*Robot, *Message)
 }
 
@@ -148,18 +173,40 @@ func (r *Robot) post(method string, data map[string]interface{}) (map[string]int
 	return result, nil
 }
 
+// هر بار صدا زدن OnMessage هندلر جدیدی اضافه می‌کند و هندلر قبلی حذف نمی‌شود؛
+// هندلرها به ترتیب ثبت اجرا می‌شوند تا وقتی یکی m.StopPropagation را صدا بزند
 func (r *Robot) OnMessage(handler func(*Robot, *Message)) {
-	r.MessageHandler = handler
-}
-
-func (r *Robot) OnCallback(buttonID string, handler func(*Robot, *Message)) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	
-	r.CallbackHandlers = append(r.CallbackHandlers, CallbackHandler{
+
+	previous := r.MessageHandler
+	if previous == nil {
+		r.MessageHandler = handler
+		return
+	}
+	r.MessageHandler = func(bot *Robot, m *Message) {
+		previous(bot, m)
+		if !m.stopped {
+			handler(bot, m)
+		}
+	}
+}
+
+// شناسه خالی همه دکمه‌ها را می‌گیرد، اما فقط وقتی هندلر خاص‌تری پیدا نشود
+func (r *Robot) OnCallback(buttonID string, handler func(*Robot, *Message), options ...CallbackOption) {
+	r.addCallbackHandler(CallbackHandler{
 		ButtonID: buttonID,
 		Handler:  handler,
-	})
+	}, options)
+}
+
+// هندلر برای همه دکمه‌هایی که شناسه‌شان با prefix شروع می‌شود؛ پیشوند بلندتر مقدم است
+func (r *Robot) OnCallbackPrefix(prefix string, handler func(*Robot, *Message), options ...CallbackOption) {
+	r.addCallbackHandler(CallbackHandler{
+		ButtonID: prefix,
+		Prefix:   true,
+		Handler:  handler,
+	}, options)
 }
 
 func (r *Robot) OnInlineQuery(handler func(*Robot, *InlineMessage)) {
@@ -180,7 +227,7 @@ func (r *Robot) processUpdate(update map[string]interface{}) {
 				Bot:     r,
 				RawData: inlineMsg,
 			}
-			go r.InlineQueryHandler(r, context)
+			r.dispatch(nil, update, func() { r.InlineQueryHandler(r, context) })
 		}
 		return
 	}
@@ -195,6 +242,9 @@ func (r *Robot) processUpdate(update map[string]interface{}) {
 		messageID, _ := newMessage["message_id"].(string)
 		senderID, _ := newMessage["sender_id"].(string)
 		text, _ := newMessage["text"].(string)
+		if r.Normalize != nil {
+			text = NormalizePersian(text, *r.Normalize)
+		}
 
 		if msgTime, ok := newMessage["time"].(float64); ok {
 			if time.Since(time.Unix(int64(msgTime), 0)) > 20*time.Second {
@@ -208,28 +258,35 @@ func (r *Robot) processUpdate(update map[string]interface{}) {
 			MessageID: messageID,
 			SenderID:  senderID,
 			Text:      text,
+			Time:      parseMessageTime(newMessage["time"]),
 			RawData:   newMessage,
+			update:    update,
+		}
+
+		if rawFile, ok := newMessage["file"].(map[string]interface{}); ok {
+			context.File = parseFile(rawFile)
 		}
 
 		if auxData, ok := newMessage["aux_data"].(map[string]interface{}); ok {
 			if buttonID, ok := auxData["button_id"].(string); ok {
-				r.mu.Lock()
-				handlers := make([]CallbackHandler, len(r.CallbackHandlers))
-				copy(handlers, r.CallbackHandlers)
-				r.mu.Unlock()
-				
-				for _, handler := range handlers {
-					if handler.ButtonID == "" || handler.ButtonID == buttonID {
-						go handler.Handler(r, context)
-						return
-					}
+				if handler := r.findCallbackHandler(context, buttonID); handler != nil {
+					r.dispatch(context, update, func() { handler.Handler(r, context) })
+					return
 				}
 			}
 		}
 
-		if r.MessageHandler != nil {
-			go r.MessageHandler(r, context)
+		if handler := r.findCommandHandler(text); handler != nil {
+			r.dispatch(context, update, func() { handler.Handler(r, context) })
+			return
+		}
+
+		if menu, node := r.findMenuNode(context); node != nil {
+			r.dispatch(context, update, func() { menu.enter(context, node, "") })
+			return
 		}
+
+		r.dispatch(context, update, func() { r.dispatchMessage(context) })
 	}
 }
 
@@ -248,6 +305,8 @@ func (r *Robot) GetUpdates(offsetID string, limit int) (map[string]interface{},
 func (r *Robot) Run() {
 	fmt.Println("🤖 Rubika Bot started running in Polling mode...")
 
+	r.publishCommands()
+
 	if r.OffsetID == "" {
 		updates, err := r.GetUpdates("", 100)
 		if err == nil {
@@ -301,6 +360,7 @@ func (r *Robot) StartWebhookServer(port string) error {
 	}
 
 	fmt.Printf("🌐 Webhook set to: %s\n", r.WebhookURL)
+	r.publishCommands()
 	fmt.Printf("🚀 Starting webhook server on port %s\n", port)
 
 	http.HandleFunc("/webhook", r.webhookHandler)
@@ -366,19 +426,34 @@ func (r *Robot) StopWebhook() error {
 	return nil
 }
 
-func (r *Robot) SendMessage(chatID, text string, options ...map[string]interface{}) (map[string]interface{}, error) {
+func (r *Robot) SendMessage(chatID, text string, options ...SendOption) (*SentMessage, error) {
+	sendOptions, err := r.sendOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// متن طولانی در چند پیام ارسال می‌شود و آخرین پیام برگردانده می‌شود
+	if sendOptions.SplitLongText && textLength(text) > MaxMessageLength {
+		sent, err := r.sendMessageChunks(chatID, text, sendOptions)
+		if err != nil {
+			return nil, err
+		}
+		if len(sent) == 0 {
+			return nil, fmt.Errorf("message text is empty")
+		}
+		return sent[len(sent)-1], nil
+	}
+
 	payload := map[string]interface{}{
 		"chat_id": chatID,
 		"text":    text,
 	}
 
-	if len(options) > 0 {
-		for key, value := range options[0] {
-			payload[key] = value
-		}
+	if err := sendOptions.apply(payload); err != nil {
+		return nil, err
 	}
 
-	return r.post("sendMessage", payload)
+	return r.send("sendMessage", chatID, payload)
 }
 
 func (r *Robot) GetMe() (map[string]interface{}, error) {
@@ -392,116 +467,73 @@ func (r *Robot) GetChat(chatID string) (map[string]interface{}, error) {
 }
 
 func (r *Robot) UploadFile(filePath, mediaType string) (string, error) {
-	uploadResult, err := r.post("requestSendFile", map[string]interface{}{
-		"type": mediaType,
-	})
-	if err != nil {
-		return "", err
-	}
-
-	data, ok := uploadResult["data"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid upload response")
-	}
-
-	uploadURL, ok := data["upload_url"].(string)
-	if !ok {
-		return "", fmt.Errorf("upload URL not found")
-	}
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
-	if err != nil {
-		return "", err
-	}
-
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return "", err
-	}
+	return r.UploadInput(context.Background(), filePath, mediaType)
+}
 
-	err = writer.Close()
-	if err != nil {
-		return "", err
+// file می‌تواند مسیر فایل، []byte، io.Reader، FSFile یا InputFile باشد
+func (r *Robot) SendFile(chatID string, file interface{}, mediaType string, options ...SendOption) (*SentMessage, error) {
+	payload := map[string]interface{}{
+		"chat_id": chatID,
 	}
 
-	// ارسال فایل
-	req, err := http.NewRequest("POST", uploadURL, body)
-	if err != nil {
-		return "", err
+	if err := r.applySendOptions(payload, options); err != nil {
+		return nil, err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := r.Client.Do(req)
-	if err != nil {
-		return "", err
+	if r.FileCache {
+		return r.sendFileCached(context.Background(), chatID, file, mediaType, payload)
 	}
-	defer resp.Body.Close()
 
-	var result map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	fileID, err := r.UploadInput(context.Background(), file, mediaType)
 	if err != nil {
-		return "", err
-	}
-
-	resultData, ok := result["data"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid upload response")
-	}
-
-	fileID, ok := resultData["file_id"].(string)
-	if !ok {
-		return "", fmt.Errorf("file ID not found")
+		return nil, err
 	}
+	payload["file_id"] = fileID
 
-	return fileID, nil
+	return r.send("sendFile", chatID, payload)
 }
 
-func (r *Robot) SendFile(chatID, filePath, mediaType string, options ...map[string]interface{}) (map[string]interface{}, error) {
-	fileID, err := r.UploadFile(filePath, mediaType)
+func (r *Robot) SendImage(chatID string, file interface{}, options ...SendOption) (*SentMessage, error) {
+	sendOptions, err := newSendOptions(options)
 	if err != nil {
 		return nil, err
 	}
 
-	payload := map[string]interface{}{
-		"chat_id": chatID,
-		"file_id": fileID,
+	imageOptions := r.ImageOptions
+	if sendOptions.ImageOptions != nil {
+		imageOptions = sendOptions.ImageOptions
 	}
 
-	if len(options) > 0 {
-		for key, value := range options[0] {
-			payload[key] = value
+	if imageOptions != nil {
+		processed, err := r.preprocessImage(file, *imageOptions)
+		if err != nil {
+			return nil, err
 		}
+		file = processed
 	}
 
-	return r.post("sendFile", payload)
-}
-
-func (r *Robot) SendImage(chatID, filePath string, options ...map[string]interface{}) (map[string]interface{}, error) {
-	return r.SendFile(chatID, filePath, "Image", options...)
+	// گزینه تصویر مصرف شد؛ بقیه گزینه‌ها به SendFile می‌رسند
+	options = append(options[:len(options):len(options)], func(o *SendOptions) error {
+		o.ImageOptions = nil
+		return nil
+	})
+	return r.SendFile(chatID, file, "Image", options...)
 }
 
-func (r *Robot) SendDocument(chatID, filePath string, options ...map[string]interface{}) (map[string]interface{}, error) {
-	return r.SendFile(chatID, filePath, "File", options...)
+func (r *Robot) SendDocument(chatID string, file interface{}, options ...SendOption) (*SentMessage, error) {
+	return r.SendFile(chatID, file, "File", options...)
 }
 
-func (r *Robot) SendMusic(chatID, filePath string, options ...map[string]interface{}) (map[string]interface{}, error) {
-	return r.SendFile(chatID, filePath, "Music", options...)
+func (r *Robot) SendMusic(chatID string, file interface{}, options ...SendOption) (*SentMessage, error) {
+	return r.SendFile(chatID, file, "Music", options...)
 }
 
-func (r *Robot) SendVoice(chatID, filePath string, options ...map[string]interface{}) (map[string]interface{}, error) {
-	return r.SendFile(chatID, filePath, "Voice", options...)
+func (r *Robot) SendVoice(chatID string, file interface{}, options ...SendOption) (*SentMessage, error) {
+	return r.SendFile(chatID, file, "Voice", options...)
 }
 
-func (r *Robot) SendGif(chatID, filePath string, options ...map[string]interface{}) (map[string]interface{}, error) {
-	return r.SendFile(chatID, filePath, "Gif", options...)
+func (r *Robot) SendGif(chatID string, file interface{}, options ...SendOption) (*SentMessage, error) {
+	return r.SendFile(chatID, file, "Gif", options...)
 }
 
 func (r *Robot) DeleteMessage(chatID, messageID string) (map[string]interface{}, error) {
@@ -511,24 +543,22 @@ func (r *Robot) DeleteMessage(chatID, messageID string) (map[string]interface{},
 	})
 }
 
-func (r *Robot) SendLocation(chatID string, latitude, longitude float64, options ...map[string]interface{}) (map[string]interface{}, error) {
+func (r *Robot) SendLocation(chatID string, latitude, longitude float64, options ...SendOption) (*SentMessage, error) {
 	payload := map[string]interface{}{
 		"chat_id":   chatID,
 		"latitude":  latitude,
 		"longitude": longitude,
 	}
 
-	if len(options) > 0 {
-		for key, value := range options[0] {
-			payload[key] = value
-		}
+	if err := r.applySendOptions(payload, options); err != nil {
+		return nil, err
 	}
 
-	return r.post("sendLocation", payload)
+	return r.send("sendLocation", chatID, payload)
 }
 
 // ارسال مخاطب
-func (r *Robot) SendContact(chatID, firstName, lastName, phoneNumber string, options ...map[string]interface{}) (map[string]interface{}, error) {
+func (r *Robot) SendContact(chatID, firstName, lastName, phoneNumber string, options ...SendOption) (*SentMessage, error) {
 	payload := map[string]interface{}{
 		"chat_id":      chatID,
 		"first_name":   firstName,
@@ -536,18 +566,16 @@ func (r *Robot) SendContact(chatID, firstName, lastName, phoneNumber string, opt
 		"phone_number": phoneNumber,
 	}
 
-	if len(options) > 0 {
-		for key, value := range options[0] {
-			payload[key] = value
-		}
+	if err := r.applySendOptions(payload, options); err != nil {
+		return nil, err
 	}
 
-	return r.post("sendContact", payload)
+	return r.send("sendContact", chatID, payload)
 }
 
 // ارسال نظرسنجی
-func (r *Robot) SendPoll(chatID, question string, options []string) (map[string]interface{}, error) {
-	return r.post("sendPoll", map[string]interface{}{
+func (r *Robot) SendPoll(chatID, question string, options []string) (*SentMessage, error) {
+	return r.send("sendPoll", chatID, map[string]interface{}{
 		"chat_id":  chatID,
 		"question": question,
 		"options":  options,
@@ -563,8 +591,16 @@ func (r *Robot) EditMessageText(chatID, messageID, text string) (map[string]inte
 	})
 }
 
-func (r *Robot) ForwardMessage(fromChatID, messageID, toChatID string, disableNotification bool) (map[string]interface{}, error) {
-	return r.post("forwardMessage", map[string]interface{}{
+func (r *Robot) EditMessageKeypad(chatID, messageID string, keypad map[string]interface{}) (map[string]interface{}, error) {
+	return r.post("editMessageKeypad", map[string]interface{}{
+		"chat_id":       chatID,
+		"message_id":    messageID,
+		"inline_keypad": keypad,
+	})
+}
+
+func (r *Robot) ForwardMessage(fromChatID, messageID, toChatID string, disableNotification bool) (*SentMessage, error) {
+	return r.send("forwardMessage", toChatID, map[string]interface{}{
 		"from_chat_id":          fromChatID,
 		"message_id":            messageID,
 		"to_chat_id":            toChatID,
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+)
+
+// تنظیمات اختیاری متدهای ارسال
+type SendOptions struct {
+	ReplyToMessageID    string
+	DisableNotification bool
+	InlineKeypad        map[string]interface{}
+	ChatKeypad          map[string]interface{}
+	ChatKeypadType      string
+	Caption             string
+	ParseMode           ParseMode
+	SplitLongText       bool
+	ImageOptions        *ImageOptions
+	Extra               map[string]interface{}
+}
+
+type SendOption func(*SendOptions) error
+
+func WithReplyTo(messageID string) SendOption {
+	return func(o *SendOptions) error {
+		o.ReplyToMessageID = messageID
+		return nil
+	}
+}
+
+func WithDisableNotification() SendOption {
+	return func(o *SendOptions) error {
+		o.DisableNotification = true
+		return nil
+	}
+}
+
+func WithInlineKeypad(keypad map[string]interface{}) SendOption {
+	return func(o *SendOptions) error {
+		o.InlineKeypad = keypad
+		return nil
+	}
+}
+
+// keypadType معمولاً "New" یا "Remove" است
+func WithChatKeypad(keypad map[string]interface{}, keypadType string) SendOption {
+	return func(o *SendOptions) error {
+		o.ChatKeypad = keypad
+		o.ChatKeypadType = keypadType
+		return nil
+	}
+}
+
+func WithCaption(caption string) SendOption {
+	return func(o *SendOptions) error {
+		o.Caption = caption
+		return nil
+	}
+}
+
+// پیش‌پردازش تصویر فقط برای همین ارسال؛ متدهای دیگر با این گزینه خطا برمی‌گردانند
+func WithImageOptions(options ImageOptions) SendOption {
+	return func(o *SendOptions) error {
+		o.ImageOptions = &options
+		return nil
+	}
+}
+
+// ارسال فیلدهای جدید API که هنوز گزینه اختصاصی ندارند
+func WithExtra(key string, value interface{}) SendOption {
+	return func(o *SendOptions) error {
+		if o.Extra == nil {
+			o.Extra = make(map[string]interface{})
+		}
+		o.Extra[key] = value
+		return nil
+	}
+}
+
+// تبدیل map قدیمی به SendOption؛ کلیدهای ناشناخته رد می‌شوند (برای آن‌ها از WithExtra استفاده کنید)
+func OptionsFromMap(values map[string]interface{}) SendOption {
+	return func(o *SendOptions) error {
+		for key, value := range values {
+			var ok bool
+			switch key {
+			case "reply_to_message_id":
+				o.ReplyToMessageID, ok = value.(string)
+			case "disable_notification":
+				o.DisableNotification, ok = value.(bool)
+			case "inline_keypad":
+				o.InlineKeypad, ok = value.(map[string]interface{})
+			case "chat_keypad":
+				o.ChatKeypad, ok = value.(map[string]interface{})
+			case "chat_keypad_type":
+				o.ChatKeypadType, ok = value.(string)
+			case "text":
+				o.Caption, ok = value.(string)
+			case "parse_mode":
+				var mode string
+				mode, ok = value.(string)
+				o.ParseMode = ParseMode(mode)
+			default:
+				return fmt.Errorf("unknown send option %q, use WithExtra for raw API fields", key)
+			}
+			if !ok {
+				return fmt.Errorf("invalid value type %T for send option %q", value, key)
+			}
+		}
+		return nil
+	}
+}
+
+func newSendOptions(options []SendOption) (*SendOptions, error) {
+	sendOptions := &SendOptions{}
+	for _, option := range options {
+		if option == nil {
+			continue
+		}
+		if err := option(sendOptions); err != nil {
+			return nil, err
+		}
+	}
+	return sendOptions, nil
+}
+
+// افزودن گزینه‌ها به payload درخواست
+func (o *SendOptions) apply(payload map[string]interface{}) error {
+	if o.ImageOptions != nil {
+		return fmt.Errorf("image options are only supported by SendImage")
+	}
+	if o.Caption != "" {
+		if _, ok := payload["text"]; ok {
+			return fmt.Errorf("caption is not supported for this method")
+		}
+		payload["text"] = o.Caption
+	}
+	if o.ReplyToMessageID != "" {
+		payload["reply_to_message_id"] = o.ReplyToMessageID
+	}
+	if o.DisableNotification {
+		payload["disable_notification"] = true
+	}
+	if o.InlineKeypad != nil {
+		payload["inline_keypad"] = o.InlineKeypad
+	}
+	if o.ChatKeypad != nil {
+		payload["chat_keypad"] = o.ChatKeypad
+		keypadType := o.ChatKeypadType
+		if keypadType == "" {
+			keypadType = "New"
+		}
+		payload["chat_keypad_type"] = keypadType
+	} else if o.ChatKeypadType != "" {
+		payload["chat_keypad_type"] = o.ChatKeypadType
+	}
+	if text, ok := payload["text"].(string); ok && o.ParseMode != "" {
+		plain, parts, err := ParseText(text, o.ParseMode)
+		if err != nil {
+			return err
+		}
+		payload["text"] = plain
+		if len(parts) > 0 {
+			payload["metadata"] = metadataPayload(parts)
+		}
+	}
+	for key, value := range o.Extra {
+		payload[key] = value
+	}
+	return nil
+}
+
+// گزینه‌های ارسال همراه با مقادیر پیش‌فرض ربات
+func (r *Robot) sendOptions(options []SendOption) (*SendOptions, error) {
+	sendOptions, err := newSendOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if sendOptions.ParseMode == "" {
+		sendOptions.ParseMode = r.ParseMode
+	}
+	if r.AutoSplit {
+		sendOptions.SplitLongText = true
+	}
+	return sendOptions, nil
+}
+
+func (r *Robot) applySendOptions(payload map[string]interface{}, options []SendOption) error {
+	sendOptions, err := r.sendOptions(options)
+	if err != nil {
+		return err
+	}
+	return sendOptions.apply(payload)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf16"
+)
+
+// حداکثر طول متن یک پیام روبیکا بر حسب واحدهای UTF-16
+var MaxMessageLength = 4096
+
+type TextChunk struct {
+	Text  string
+	Parts []MetadataPart
+}
+
+func WithSplitLongText() SendOption {
+	return func(o *SendOptions) error {
+		o.SplitLongText = true
+		return nil
+	}
+}
+
+// تقسیم خودکار متن‌های طولانی برای همه پیام‌ها
+func WithAutoSplit() func(*Robot) {
+	return func(r *Robot) {
+		r.AutoSplit = true
+	}
+}
+
+func textLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// تقسیم متن به بخش‌هایی با حداکثر طول limit؛ اولویت با مرز پاراگراف، سپس خط و سپس کلمه است
+// و متادیتای قالب‌بندی برای هر بخش بریده و دوباره ایندکس می‌شود
+func SplitText(text string, parts []MetadataPart, limit int) []TextChunk {
+	runes := []rune(text)
+
+	// offsets[i] موقعیت UTF-16 ابتدای رون i است
+	offsets := make([]int, len(runes)+1)
+	for i, ch := range runes {
+		offsets[i+1] = offsets[i] + len(utf16.Encode([]rune{ch}))
+	}
+
+	var chunks []TextChunk
+	start := 0
+	for start < len(runes) {
+		end := len(runes)
+		if offsets[end]-offsets[start] > limit {
+			end = splitPoint(runes, offsets, start, limit)
+		}
+
+		// حذف فاصله‌های انتهای بخش و ابتدای بخش بعدی
+		textEnd := end
+		for textEnd > start && unicode.IsSpace(runes[textEnd-1]) {
+			textEnd--
+		}
+
+		if textEnd > start {
+			chunks = append(chunks, TextChunk{
+				Text:  string(runes[start:textEnd]),
+				Parts: clipParts(parts, offsets[start], offsets[textEnd]),
+			})
+		}
+
+		start = end
+		for start < len(runes) && unicode.IsSpace(runes[start]) {
+			start++
+		}
+	}
+
+	return chunks
+}
+
+func splitPoint(runes []rune, offsets []int, start, limit int) int {
+	maxEnd := start
+	for maxEnd < len(runes) && offsets[maxEnd+1]-offsets[start] <= limit {
+		maxEnd++
+	}
+
+	// پاراگراف، خط، کلمه
+	for _, isBoundary := range []func(i int) bool{
+		func(i int) bool { return runes[i] == '\n' && i > start && runes[i-1] == '\n' },
+		func(i int) bool { return runes[i] == '\n' },
+		func(i int) bool { return unicode.IsSpace(runes[i]) },
+	} {
+		for i := maxEnd - 1; i > start; i-- {
+			if isBoundary(i) {
+				return i + 1
+			}
+		}
+	}
+
+	if maxEnd == start {
+		return start + 1
+	}
+	return maxEnd
+}
+
+func clipParts(parts []MetadataPart, from, to int) []MetadataPart {
+	var clipped []MetadataPart
+	for _, part := range parts {
+		partStart := part.FromIndex
+		partEnd := part.FromIndex + part.Length
+		if partEnd <= from || partStart >= to {
+			continue
+		}
+		if partStart < from {
+			partStart = from
+		}
+		if partEnd > to {
+			partEnd = to
+		}
+		part.FromIndex = partStart - from
+		part.Length = partEnd - partStart
+		clipped = append(clipped, part)
+	}
+	return clipped
+}
+
+// ارسال متن طولانی در چند پیام به ترتیب؛ ریپلای روی اولین و کیبورد روی آخرین پیام قرار می‌گیرد
+func (r *Robot) SendLongMessage(chatID, text string, options ...SendOption) ([]*SentMessage, error) {
+	sendOptions, err := r.sendOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return r.sendMessageChunks(chatID, text, sendOptions)
+}
+
+func (r *Robot) sendMessageChunks(chatID, text string, sendOptions *SendOptions) ([]*SentMessage, error) {
+	plain, parts, err := ParseText(text, sendOptions.ParseMode)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := SplitText(plain, parts, MaxMessageLength)
+	sent := make([]*SentMessage, 0, len(chunks))
+
+	for i, chunk := range chunks {
+		chunkOptions := *sendOptions
+		chunkOptions.ParseMode = ""
+		if i > 0 {
+			chunkOptions.ReplyToMessageID = ""
+		}
+		if i < len(chunks)-1 {
+			chunkOptions.InlineKeypad = nil
+			chunkOptions.ChatKeypad = nil
+			chunkOptions.ChatKeypadType = ""
+		}
+
+		payload := map[string]interface{}{
+			"chat_id": chatID,
+			"text":    chunk.Text,
+		}
+		if err := chunkOptions.apply(payload); err != nil {
+			return sent, err
+		}
+		if len(chunk.Parts) > 0 {
+			payload["metadata"] = metadataPayload(chunk.Parts)
+		}
+
+		message, err := r.send("sendMessage", chatID, payload)
+		if err != nil {
+			return sent, err
+		}
+		sent = append(sent, message)
+	}
+
+	return sent, nil
+}
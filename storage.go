@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sync"
+)
+
+// ذخیره‌سازی کلید/مقدار برای کش file_id و داده‌های ماندگار ربات
+type Storage interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+type MemoryStorage struct {
+	mu   sync.RWMutex
+	data map[string]string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		data: make(map[string]string),
+	}
+}
+
+func (s *MemoryStorage) Get(key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.data[key]
+	return value, ok, nil
+}
+
+func (s *MemoryStorage) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = value
+	return nil
+}
+
+func (s *MemoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+func WithStorage(storage Storage) func(*Robot) {
+	return func(r *Robot) {
+		r.Storage = storage
+	}
+}
+
+func (r *Robot) storage() Storage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Storage == nil {
+		r.Storage = NewMemoryStorage()
+	}
+	return r.Storage
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// رجیستری قالب‌های پیام؛ هر قالب می‌تواند در front matter (YAML) حالت قالب‌بندی و کیبورد خود را تعریف کند:
+//
+//	---
+//	parse_mode: Markdown
+//	keypad:
+//	  type: inline
+//	  rows:
+//	    - buttons:
+//	        - id: btn_info
+//	          text: 📊 اطلاعات
+//	---
+//	سلام {{.Name}}!
+type Templates struct {
+	mu        sync.RWMutex
+	templates map[string]*messageTemplate
+	funcs     template.FuncMap
+}
+
+type messageTemplate struct {
+	body       *template.Template
+	parseMode  ParseMode
+	keypadType string
+	rows       [][]templateButton
+}
+
+type templateButton struct {
+	id         string
+	buttonType string
+	text       *template.Template
+}
+
+func NewTemplates() *Templates {
+	return &Templates{
+		templates: make(map[string]*messageTemplate),
+		funcs:     defaultTemplateFuncs(),
+	}
+}
+
+func defaultTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"escape":     Escape,
+		"escapeHTML": EscapeHTML,
+		"faNum":      FormatPersianNumber,
+		"faDigits":   ToPersianDigits,
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"jdate": FormatJalaliPersian,
+	}
+}
+
+func WithTemplates(templates *Templates) func(*Robot) {
+	return func(r *Robot) {
+		r.Templates = templates
+	}
+}
+
+// افزودن توابع کمکی؛ باید قبل از بارگذاری قالب‌ها فراخوانی شود
+func (t *Templates) Funcs(funcs template.FuncMap) *Templates {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, fn := range funcs {
+		t.funcs[name] = fn
+	}
+	return t
+}
+
+// بارگذاری قالب‌ها از embed.FS یا هر fs.FS؛ نام قالب نام فایل بدون پسوند است
+func (t *Templates) LoadFS(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no templates match %q", pattern)
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+		if err := t.Parse(name, string(data)); err != nil {
+			return fmt.Errorf("template %s: %v", file, err)
+		}
+	}
+	return nil
+}
+
+func (t *Templates) LoadDir(dir string) error {
+	return t.LoadFS(os.DirFS(dir), "*.tmpl")
+}
+
+func splitFrontMatter(source string) (string, string) {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	if !strings.HasPrefix(source, "---\n") {
+		return "", source
+	}
+	rest := source[4:]
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if strings.HasSuffix(rest, "\n---") {
+			return rest[:len(rest)-4], ""
+		}
+		return "", source
+	}
+	return rest[:end], rest[end+5:]
+}
+
+func (t *Templates) Parse(name, source string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frontMatter, body := splitFrontMatter(source)
+
+	tmpl := &messageTemplate{}
+	var err error
+	tmpl.body, err = template.New(name).Funcs(t.funcs).Parse(body)
+	if err != nil {
+		return err
+	}
+
+	if frontMatter != "" {
+		if err := t.parseFrontMatter(tmpl, frontMatter); err != nil {
+			return err
+		}
+	}
+
+	t.templates[name] = tmpl
+	return nil
+}
+
+func (t *Templates) parseFrontMatter(tmpl *messageTemplate, frontMatter string) error {
+	parsed, err := parseYAML(frontMatter)
+	if err != nil {
+		return err
+	}
+	meta, ok := parsed.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("front matter must be a mapping")
+	}
+
+	if mode, ok := meta["parse_mode"].(string); ok {
+		tmpl.parseMode = ParseMode(mode)
+	}
+
+	keypad, ok := meta["keypad"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	tmpl.keypadType, _ = keypad["type"].(string)
+	if tmpl.keypadType == "" {
+		tmpl.keypadType = "inline"
+	}
+	if tmpl.keypadType != "inline" && tmpl.keypadType != "chat" {
+		return fmt.Errorf("unknown keypad type %q", tmpl.keypadType)
+	}
+
+	rows, _ := keypad["rows"].([]interface{})
+	for _, rawRow := range rows {
+		row, _ := rawRow.(map[string]interface{})
+		buttons, _ := row["buttons"].([]interface{})
+
+		var templateRow []templateButton
+		for _, rawButton := range buttons {
+			button, _ := rawButton.(map[string]interface{})
+			id, _ := button["id"].(string)
+			text, _ := button["text"].(string)
+			if id == "" || text == "" {
+				return fmt.Errorf("keypad button needs id and text")
+			}
+
+			textTemplate, err := template.New(id).Funcs(t.funcs).Parse(text)
+			if err != nil {
+				return err
+			}
+			buttonType, _ := button["type"].(string)
+			templateRow = append(templateRow, templateButton{id: id, buttonType: buttonType, text: textTemplate})
+		}
+		tmpl.rows = append(tmpl.rows, templateRow)
+	}
+	return nil
+}
+
+// اجرای قالب و برگرداندن متن به همراه گزینه‌های ارسال (حالت قالب‌بندی و کیبورد)
+func (t *Templates) Render(name string, data interface{}) (string, []SendOption, error) {
+	t.mu.RLock()
+	tmpl, ok := t.templates[name]
+	t.mu.RUnlock()
+	if !ok {
+		return "", nil, fmt.Errorf("template %q not found", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.body.Execute(&buf, data); err != nil {
+		return "", nil, err
+	}
+
+	var options []SendOption
+	if tmpl.parseMode != "" {
+		options = append(options, WithParseMode(tmpl.parseMode))
+	}
+
+	if len(tmpl.rows) > 0 {
+		rows := make([]map[string]interface{}, 0, len(tmpl.rows))
+		for _, row := range tmpl.rows {
+			buttons := make([]map[string]interface{}, 0, len(row))
+			for _, button := range row {
+				var text bytes.Buffer
+				if err := button.text.Execute(&text, data); err != nil {
+					return "", nil, err
+				}
+				if button.buttonType != "" {
+					buttons = append(buttons, CreateInlineButton(text.String(), button.id, button.buttonType))
+				} else {
+					buttons = append(buttons, CreateInlineButton(text.String(), button.id))
+				}
+			}
+			rows = append(rows, CreateButtonRow(buttons...))
+		}
+
+		keypad := CreateInlineKeypad(rows)
+		if tmpl.keypadType == "chat" {
+			keypad["resize_keyboard"] = true
+			options = append(options, WithChatKeypad(keypad, "New"))
+		} else {
+			options = append(options, WithInlineKeypad(keypad))
+		}
+	}
+
+	return strings.TrimSpace(buf.String()), options, nil
+}
+
+func (r *Robot) RenderAndSend(chatID, name string, data interface{}, options ...SendOption) (*SentMessage, error) {
+	if r.Templates == nil {
+		return nil, fmt.Errorf("templates are not configured")
+	}
+
+	text, templateOptions, err := r.Templates.Render(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.SendMessage(chatID, text, append(templateOptions, options...)...)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// فایل ورودی برای ارسال از حافظه یا هر io.Reader؛ Size صفر یا منفی یعنی حجم نامشخص
+type InputFile struct {
+	Name   string
+	Reader io.Reader
+	Size   int64
+}
+
+// فایل داخل یک fs.FS مثل embed.FS
+type FSFile struct {
+	FS   fs.FS
+	Name string
+}
+
+func FileFromBytes(name string, data []byte) InputFile {
+	return InputFile{
+		Name:   name,
+		Reader: bytes.NewReader(data),
+		Size:   int64(len(data)),
+	}
+}
+
+func FileFromReader(name string, reader io.Reader, size int64) InputFile {
+	return InputFile{
+		Name:   name,
+		Reader: reader,
+		Size:   size,
+	}
+}
+
+func FileFromFS(fsys fs.FS, name string) FSFile {
+	return FSFile{
+		FS:   fsys,
+		Name: name,
+	}
+}
+
+// تبدیل ورودی‌های پشتیبانی‌شده (مسیر، []byte، io.Reader، FSFile، InputFile) به InputFile
+func openInputFile(file interface{}) (InputFile, func() error, error) {
+	noop := func() error { return nil }
+
+	switch f := file.(type) {
+	case string:
+		osFile, err := os.Open(f)
+		if err != nil {
+			return InputFile{}, nil, err
+		}
+		info, err := osFile.Stat()
+		if err != nil {
+			osFile.Close()
+			return InputFile{}, nil, err
+		}
+		return InputFile{Name: filepath.Base(f), Reader: osFile, Size: info.Size()}, osFile.Close, nil
+
+	case []byte:
+		return FileFromBytes("file", f), noop, nil
+
+	case InputFile:
+		if f.Reader == nil {
+			return InputFile{}, nil, fmt.Errorf("input file has no reader")
+		}
+		if f.Name == "" {
+			f.Name = "file"
+		}
+		// InputFile{Name, Reader} بدون Size نباید ContentLength را فقط سربار multipart بگذارد
+		if f.Size == 0 {
+			f.Size = -1
+		}
+		return f, noop, nil
+
+	case FSFile:
+		fsFile, err := f.FS.Open(f.Name)
+		if err != nil {
+			return InputFile{}, nil, err
+		}
+		info, err := fsFile.Stat()
+		if err != nil {
+			fsFile.Close()
+			return InputFile{}, nil, err
+		}
+		return InputFile{Name: filepath.Base(f.Name), Reader: fsFile, Size: info.Size()}, fsFile.Close, nil
+
+	case io.Reader:
+		input := InputFile{Name: "file", Reader: f, Size: -1}
+		if named, ok := f.(interface{ Name() string }); ok {
+			input.Name = filepath.Base(named.Name())
+		}
+		if stater, ok := f.(interface{ Stat() (os.FileInfo, error) }); ok {
+			if info, err := stater.Stat(); err == nil {
+				input.Size = info.Size()
+			}
+		}
+		return input, noop, nil
+	}
+
+	return InputFile{}, nil, fmt.Errorf("unsupported file type %T", file)
+}
+
+func (r *Robot) requestUploadURL(mediaType string) (string, error) {
+	uploadResult, err := r.post("requestSendFile", map[string]interface{}{
+		"type": mediaType,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	data, ok := uploadResult["data"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid upload response")
+	}
+
+	uploadURL, ok := data["upload_url"].(string)
+	if !ok {
+		return "", fmt.Errorf("upload URL not found")
+	}
+
+	return uploadURL, nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// اندازه بخش‌های multipart به جز محتوای خود فایل
+func multipartOverhead(boundary, name string) (int64, error) {
+	counter := &countingWriter{}
+	writer := multipart.NewWriter(counter)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+	if _, err := writer.CreateFormFile("file", name); err != nil {
+		return 0, err
+	}
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+	return counter.n, nil
+}
+
+// آپلود جریانی بدون نگه‌داشتن کل فایل در حافظه؛ size منفی یعنی اندازه نامشخص
+func (r *Robot) UploadReader(ctx context.Context, reader io.Reader, name, mediaType string, size int64, options ...UploadOption) (string, error) {
+	config := newUploadConfig(options)
+	if config.progress != nil {
+		reader = newProgressReader(reader, size, config)
+	}
+
+	uploadURL, err := r.requestUploadURL(mediaType)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, guard, cancel := r.transferContext(ctx)
+	defer cancel()
+	reader = guard.reader(reader)
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	contentLength := int64(-1)
+	if size >= 0 {
+		overhead, err := multipartOverhead(writer.Boundary(), name)
+		if err != nil {
+			return "", err
+		}
+		contentLength = overhead + size
+	}
+
+	go func() {
+		part, err := writer.CreateFormFile("file", name)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		if _, err := io.Copy(part, reader); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(writer.Close())
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, pr)
+	if err != nil {
+		pr.Close()
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.ContentLength = contentLength
+
+	resp, err := r.transferClient().Do(req)
+	if err != nil {
+		pr.CloseWithError(err)
+		return "", guard.err(err)
+	}
+	defer resp.Body.Close()
+	pr.Close()
+
+	var result map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return "", guard.err(err)
+	}
+
+	resultData, ok := result["data"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid upload response")
+	}
+
+	fileID, ok := resultData["file_id"].(string)
+	if !ok {
+		return "", fmt.Errorf("file ID not found")
+	}
+
+	return fileID, nil
+}
+
+func (r *Robot) UploadInput(ctx context.Context, file interface{}, mediaType string, options ...UploadOption) (string, error) {
+	input, closeFn, err := openInputFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer closeFn()
+
+	return r.UploadReader(ctx, input.Reader, input.Name, mediaType, input.Size, options...)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// پارسر زیرمجموعه‌ای از YAML (نگاشت، لیست، رشته و بلوک |) برای front matter، کاتالوگ‌ها و منوها؛
+// همه مقادیر ساده به صورت string برگردانده می‌شوند و ساختارهای درون‌خطی [ ] و { } خطا می‌دهند
+func parseYAML(data string) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		content := strings.TrimRight(stripYAMLComment(raw), " \t")
+		if strings.TrimSpace(content) == "" {
+			lines = append(lines, yamlLine{number: i + 1, indent: -1, raw: raw})
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(content, " "), "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs are not allowed for indentation", i+1)
+		}
+		indent := len(content) - len(strings.TrimLeft(content, " "))
+		lines = append(lines, yamlLine{number: i + 1, indent: indent, text: content[indent:], raw: raw})
+	}
+
+	p := &yamlParser{lines: lines}
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	value, err := p.parseBlock(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("yaml line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+	raw    string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func stripYAMLComment(line string) string {
+	inSingle, inDouble := false, false
+	for i, ch := range line {
+		switch {
+		case ch == '\'' && !inDouble:
+			inSingle = !inSingle
+		case ch == '"' && !inSingle && (i == 0 || line[i-1] != '\\'):
+			inDouble = !inDouble
+		case ch == '#' && !inSingle && !inDouble && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].indent < 0 {
+		p.pos++
+	}
+}
+
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	p.skipBlank()
+	line := p.lines[p.pos]
+	if line.text == "-" || strings.HasPrefix(line.text, "- ") {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
+	var items []interface{}
+
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent != indent {
+			break
+		}
+		line := p.lines[p.pos]
+		if line.text != "-" && !strings.HasPrefix(line.text, "- ") {
+			break
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if rest == "" {
+			p.pos++
+			value, err := p.parseChild(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			continue
+		}
+
+		// "- key: value" یا "- - item" به عنوان بلوکی با تورفتگی بیشتر خوانده می‌شود
+		childIndent := indent + len(line.text) - len(rest)
+		if rest == "-" || strings.HasPrefix(rest, "- ") || isYAMLKey(rest) {
+			p.lines[p.pos] = yamlLine{number: line.number, indent: childIndent, text: rest, raw: line.raw}
+			value, err := p.parseBlock(childIndent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			continue
+		}
+
+		value, err := parseYAMLScalar(rest, line.number)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+		p.pos++
+	}
+
+	return items, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent != indent {
+			break
+		}
+		line := p.lines[p.pos]
+		if !isYAMLKey(line.text) {
+			if _, err := parseYAMLScalar(line.text, line.number); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("yaml line %d: expected key: value", line.number)
+		}
+
+		key, rest := splitYAMLKey(line.text)
+		if unquoted, err := parseYAMLScalar(key, line.number); err == nil {
+			key = unquoted
+		}
+		if _, exists := result[key]; exists {
+			return nil, fmt.Errorf("yaml line %d: duplicate key %q", line.number, key)
+		}
+		p.pos++
+
+		switch {
+		case rest == "":
+			value, err := p.parseChild(indent)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+		case rest == "|" || rest == "|-":
+			result[key] = p.parseLiteral(indent, rest == "|-")
+		default:
+			value, err := parseYAMLScalar(rest, line.number)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+		}
+	}
+
+	return result, nil
+}
+
+// بلوک فرزند؛ لیست می‌تواند هم‌تراز با کلید والد باشد
+func (p *yamlParser) parseChild(parentIndent int) (interface{}, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return "", nil
+	}
+	line := p.lines[p.pos]
+	if line.indent > parentIndent {
+		return p.parseBlock(line.indent)
+	}
+	if line.indent == parentIndent && (line.text == "-" || strings.HasPrefix(line.text, "- ")) {
+		return p.parseSequence(line.indent)
+	}
+	return "", nil
+}
+
+func (p *yamlParser) parseLiteral(parentIndent int, strip bool) string {
+	var body []string
+	blockIndent := -1
+
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent >= 0 && line.indent <= parentIndent {
+			break
+		}
+		if line.indent >= 0 && blockIndent < 0 {
+			blockIndent = line.indent
+		}
+		body = append(body, line.raw)
+		p.pos++
+	}
+
+	for i, raw := range body {
+		if len(raw) >= blockIndent && blockIndent >= 0 {
+			body[i] = raw[blockIndent:]
+		} else {
+			body[i] = strings.TrimLeft(raw, " ")
+		}
+	}
+
+	text := strings.Join(body, "\n")
+	text = strings.TrimRight(text, "\n")
+	if !strip {
+		text += "\n"
+	}
+	return text
+}
+
+func isYAMLKey(text string) bool {
+	key, _ := splitYAMLKey(text)
+	return key != ""
+}
+
+func splitYAMLKey(text string) (string, string) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", ""
+	}
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, `'`) {
+		quote := text[:1]
+		end := strings.Index(text[1:], quote)
+		if end < 0 {
+			return "", ""
+		}
+		after := text[end+2:]
+		if after == ":" || strings.HasPrefix(after, ": ") {
+			return text[:end+2], strings.TrimSpace(strings.TrimPrefix(after, ":"))
+		}
+		return "", ""
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		}
+	}
+	return "", ""
+}
+
+func parseYAMLScalar(text string, lineNumber int) (string, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return "", fmt.Errorf("yaml line %d: invalid quoted string", lineNumber)
+		}
+		return value, nil
+	case strings.HasPrefix(text, `'`):
+		if len(text) < 2 || !strings.HasSuffix(text, `'`) {
+			return "", fmt.Errorf("yaml line %d: invalid quoted string", lineNumber)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text == "~" || text == "null":
+		return "", nil
+	case strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{"):
+		// لیست و نگاشت درون‌خطی ([1, 2] و {a: 1}) پشتیبانی نمی‌شوند و نباید رشته خوانده شوند
+		return "", fmt.Errorf("yaml line %d: unsupported YAML construct %q, use block style or quote the value", lineNumber, text)
+	case text == ">" || text == ">-" || text == "|+":
+		return "", fmt.Errorf("yaml line %d: unsupported YAML construct %q, use | or |-", lineNumber, text)
+	}
+	return text, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{"empty", "", nil},
+		{"mapping", "a: 1\nb: متن", map[string]interface{}{"a": "1", "b": "متن"}},
+		{"nested mapping", "a:\n  b: x\n  c: y", map[string]interface{}{
+			"a": map[string]interface{}{"b": "x", "c": "y"},
+		}},
+		{"sequence", "- a\n- b", []interface{}{"a", "b"}},
+		{"sequence at parent indent", "items:\n- a\n- b", map[string]interface{}{
+			"items": []interface{}{"a", "b"},
+		}},
+		{"sequence of mappings", "- id: 1\n  text: x\n- id: 2", []interface{}{
+			map[string]interface{}{"id": "1", "text": "x"},
+			map[string]interface{}{"id": "2"},
+		}},
+		{"quoted", `a: "x: #y"` + "\nb: 'it''s'", map[string]interface{}{"a": "x: #y", "b": "it's"}},
+		{"quoted key", `"a b": 1`, map[string]interface{}{"a b": "1"}},
+		{"comments", "# header\na: 1 # note\nb: x#y", map[string]interface{}{"a": "1", "b": "x#y"}},
+		{"null", "a: ~\nb: null", map[string]interface{}{"a": "", "b": ""}},
+		{"literal", "a: |\n  line 1\n  line 2\nb: x", map[string]interface{}{"a": "line 1\nline 2\n", "b": "x"}},
+		{"literal strip", "a: |-\n  line", map[string]interface{}{"a": "line"}},
+		{"quoted flow", `a: "[1, 2]"`, map[string]interface{}{"a": "[1, 2]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML(tt.input)
+			if err != nil {
+				t.Fatalf("parseYAML(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"flow sequence", "a: [1, 2]", "unsupported YAML construct"},
+		{"flow mapping", "a: {b: 1}", "unsupported YAML construct"},
+		{"flow item", "- {a: 1}", "unsupported YAML construct"},
+		{"top level flow", "{a: 1}", "unsupported YAML construct"},
+		{"folded", "a: >\n  text", "unsupported YAML construct"},
+		{"tab indent", "a:\n\tb: 1", "tabs are not allowed"},
+		{"duplicate key", "a: 1\na: 2", "duplicate key"},
+		{"bad indent", "a: 1\n  b: 2", "unexpected indentation"},
+		{"not a key", "a: 1\nb", "expected key: value"},
+		{"bad quote", `a: "x`, "invalid quoted string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAML(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseYAML(%q) error = %v, want %q", tt.input, err, tt.err)
+			}
+		})
+	}
+}